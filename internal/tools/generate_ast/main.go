@@ -18,7 +18,7 @@ func defineAst(outputDir string, baseName string, types []string) error {
 	_, err := buf.WriteString(`
 	// this file is auto-generated with bin/generate_ast
 	// DO NOT EDIT
-	package main
+	package lox
 
 	`)
 	if err != nil {
@@ -150,7 +150,9 @@ func main() {
 		"Var: name Token",
 		"Assign: name Token, value Expr",
 		"Logical: left Expr, operator Token, right Expr",
-		"Call: callee Expr, paren Token, arguments []Expr",
+		"Call: callee Expr, paren Token, arguments []Expr, namedArguments []NamedArgument",
+		"Get: object Expr, name Token",
+		"Set: object Expr, name Token, value Expr",
 	})
 	if err != nil {
 		log.Fatal(err)
@@ -159,12 +161,15 @@ func main() {
 	err = defineAst(outputDir, "Stmt", []string{
 		"StmtExpression: expression Expr",
 		"StmtPrint: expression Expr",
-		"StmtVar: name Token, initializer Expr",
+		"StmtVar: name Token, initializer Expr, doc string",
 		"StmtBlock: statements []Stmt",
 		"StmtIf: condition Expr, thenBranch Stmt, elseBranch Stmt",
-		"StmtWhile: condition Expr, body Stmt",
-		"StmtFunction: name Token, params []Token, body StmtBlock",
+		"StmtWhile: keyword Token, condition Expr, body Stmt",
+		"StmtFunction: name Token, params []Token, body StmtBlock, doc string",
 		"StmtReturn: returnKeyword Token, value Expr",
+		"StmtAssert: keyword Token, condition Expr, message Expr, source string",
+		"StmtEnum: name Token, members []Token, doc string",
+		"StmtRecord: name Token, fields []Token, doc string",
 	})
 
 	if err != nil {
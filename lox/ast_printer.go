@@ -0,0 +1,161 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+type AstPrinter struct{}
+
+func (a AstPrinter) Print(expr Expr) string {
+	s, _ := expr.Accept(a)
+	return s.(string)
+}
+
+// PrintStmt prints a statement, and everything in it, as an s-expression like Print does for expressions.
+func (a AstPrinter) PrintStmt(stmt Stmt) string {
+	s, _ := stmt.Accept(a)
+	return s.(string)
+}
+
+// parenthesize prints name followed by parts, which can be expressions, statements or strings.
+// Nil parts, like a missing initializer, are left out.
+func (a AstPrinter) parenthesize(name string, parts ...any) string {
+	var sb strings.Builder
+	sb.WriteString("(")
+	sb.WriteString(name)
+
+	for _, part := range parts {
+		var str string
+		switch p := part.(type) {
+		case nil:
+			continue
+		case Expr:
+			str = a.Print(p)
+		case Stmt:
+			str = a.PrintStmt(p)
+		case string:
+			str = p
+		}
+
+		sb.WriteString(" ")
+		sb.WriteString(str)
+	}
+
+	sb.WriteString(")")
+	return sb.String()
+}
+
+func (a AstPrinter) VisitUnary(expr Unary) (any, error) {
+	return a.parenthesize(expr.operator.lexeme, expr.right), nil
+}
+
+func (a AstPrinter) VisitBinary(expr Binary) (any, error) {
+	return a.parenthesize(expr.operator.lexeme, expr.left, expr.right), nil
+}
+
+func (a AstPrinter) VisitGrouping(expr Grouping) (any, error) {
+	return a.parenthesize("group", expr.expression), nil
+}
+
+func (a AstPrinter) VisitLiteral(expr Literal) (any, error) {
+	switch v := expr.value.(type) {
+	case nil:
+		return "nil", nil
+	case string:
+		return fmt.Sprintf("%q", v), nil
+	}
+
+	return fmt.Sprintf("%v", expr.value), nil
+}
+
+func (a AstPrinter) VisitVar(expr Var) (any, error) {
+	return fmt.Sprintf("%v", expr.name.lexeme), nil
+}
+
+func (a AstPrinter) VisitAssign(expr Assign) (any, error) {
+	return a.parenthesize("= "+expr.name.lexeme, expr.value), nil
+}
+
+func (a AstPrinter) VisitLogical(expr Logical) (any, error) {
+	return a.parenthesize(expr.operator.lexeme, expr.left, expr.right), nil
+}
+
+func (a AstPrinter) VisitCall(expr Call) (any, error) {
+	parts := []any{expr.callee}
+	for _, argument := range expr.arguments {
+		parts = append(parts, argument)
+	}
+	for _, argument := range expr.namedArguments {
+		parts = append(parts, a.parenthesize(argument.name.lexeme+":", argument.value))
+	}
+
+	return a.parenthesize("call", parts...), nil
+}
+
+func (a AstPrinter) VisitGet(expr Get) (any, error) {
+	return a.parenthesize("get "+expr.name.lexeme, expr.object), nil
+}
+
+func (a AstPrinter) VisitSet(expr Set) (any, error) {
+	return a.parenthesize("set "+expr.name.lexeme, expr.object, expr.value), nil
+}
+
+func (a AstPrinter) VisitStmtExpression(stmt StmtExpression) (any, error) {
+	return a.parenthesize("expr", stmt.expression), nil
+}
+
+func (a AstPrinter) VisitStmtPrint(stmt StmtPrint) (any, error) {
+	return a.parenthesize("print", stmt.expression), nil
+}
+
+func (a AstPrinter) VisitStmtVar(stmt StmtVar) (any, error) {
+	return a.parenthesize("var "+stmt.name.lexeme, stmt.initializer), nil
+}
+
+func (a AstPrinter) VisitStmtBlock(stmt StmtBlock) (any, error) {
+	parts := make([]any, 0, len(stmt.statements))
+	for _, s := range stmt.statements {
+		parts = append(parts, s)
+	}
+
+	return a.parenthesize("block", parts...), nil
+}
+
+func (a AstPrinter) VisitStmtIf(stmt StmtIf) (any, error) {
+	return a.parenthesize("if", stmt.condition, stmt.thenBranch, stmt.elseBranch), nil
+}
+
+func (a AstPrinter) VisitStmtWhile(stmt StmtWhile) (any, error) {
+	return a.parenthesize("while", stmt.condition, stmt.body), nil
+}
+
+func (a AstPrinter) VisitStmtFunction(stmt StmtFunction) (any, error) {
+	return a.parenthesize("fun "+stmt.name.lexeme, a.tokenList(stmt.params), stmt.body), nil
+}
+
+func (a AstPrinter) VisitStmtReturn(stmt StmtReturn) (any, error) {
+	return a.parenthesize("return", stmt.value), nil
+}
+
+func (a AstPrinter) VisitStmtAssert(stmt StmtAssert) (any, error) {
+	return a.parenthesize("assert", stmt.condition, stmt.message), nil
+}
+
+func (a AstPrinter) VisitStmtEnum(stmt StmtEnum) (any, error) {
+	return a.parenthesize("enum "+stmt.name.lexeme, a.tokenList(stmt.members)), nil
+}
+
+func (a AstPrinter) VisitStmtRecord(stmt StmtRecord) (any, error) {
+	return a.parenthesize("record "+stmt.name.lexeme, a.tokenList(stmt.fields)), nil
+}
+
+// tokenList prints names, like a function's parameters, as a parenthesized list.
+func (a AstPrinter) tokenList(tokens []Token) string {
+	names := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		names = append(names, token.lexeme)
+	}
+
+	return "(" + strings.Join(names, " ") + ")"
+}
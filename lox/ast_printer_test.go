@@ -0,0 +1,102 @@
+package lox
+
+import (
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestAstPrinter_Print(t *testing.T) {
+	type args struct {
+		expr Expr
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "basic",
+			args: args{
+				expr: Binary{
+					left: Literal{value: 1},
+					operator: Token{
+						tokenType: PLUS,
+						lexeme:    "+",
+						literal:   nil,
+						line:      0,
+					},
+					right: Literal{value: 2},
+				},
+			},
+			want: "(+ 1 2)",
+		},
+		{
+			name: "complex",
+			args: args{
+				expr: Binary{
+					left: Unary{
+						operator: Token{
+							tokenType: MINUS,
+							lexeme:    "-",
+							literal:   nil,
+							line:      0,
+						},
+						right: Literal{value: 123},
+					},
+					operator: Token{
+						tokenType: STAR,
+						lexeme:    "*",
+						literal:   nil,
+						line:      0,
+					},
+					right: Grouping{expression: Literal{value: 45.67}},
+				},
+			},
+			want: "(* (- 123) (group 45.67))",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := AstPrinter{}
+			if got := a.Print(tt.args.expr); got != tt.want {
+				t.Errorf("Print() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAstPrinter_PrintStmt(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"var a;", "(var a)"},
+		{"var a = 1;", "(var a 1)"},
+		{"fun add(a, b) { return a + b; }", "(fun add (a b) (block (return (+ a b))))"},
+		{"print add(1, \"two\");", "(print (call add 1 \"two\"))"},
+		{"if (a) print nil; else a = 1;", "(if a (print nil) (expr (= a 1)))"},
+		{"while (a < 3) { a = a + 1; }", "(while (< a 3) (block (expr (= a (+ a 1)))))"},
+		{"assert a != 1, \"oops\";", "(assert (!= a 1) \"oops\")"},
+		{"enum Color { Red, Green }", "(enum Color (Red Green))"},
+		{"record P(x, y);", "(record P (x y))"},
+		{"p.with(x: 1).x = f();", "(expr (set x (call (get with p) (x: 1)) (call f)))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			is := is2.New(t)
+
+			lox := Lox{}
+			scanner := Scanner{lox: &lox, source: tt.source, line: 1}
+			tokens, err := scanner.scanTokens()
+			is.NoErr(err)
+			parser := Parser{Lox: &lox, Tokens: tokens}
+			statements, err := parser.Parse()
+			is.NoErr(err)
+			is.Equal(len(statements), 1)
+
+			is.Equal(AstPrinter{}.PrintStmt(statements[0]), tt.want)
+		})
+	}
+}
@@ -0,0 +1,427 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// GoObject is a Go value bound into Lox, see ToValue. Lox code can read and write the exported fields of structs,
+// and call their exported methods, by their Go names:
+//
+//	config.Timeout = config.Timeout * 2;
+//	config.Reload();
+//
+// Slices, arrays and maps aren't copied into Lox, they're shared with Go. They have a length property,
+// and get(key) and set(key, value) methods, and maps also have has(key), delete(key) and keys().
+type GoObject struct {
+	value reflect.Value
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ToValue converts a Go value to a Lox one. Booleans and strings stay as they are, all of Go's number types
+// become float64, functions become native functions, and nil pointers, maps, slices and interfaces become nil.
+// Any other value, such as a struct or pointer to one, is bound as a GoObject. Lox values are returned unchanged.
+func ToValue(v any) Value {
+	return toNamedValue(v, "func")
+}
+
+// toNamedValue converts v, with name used for it in error messages if it's a function.
+func toNamedValue(v any, name string) Value {
+	switch v := v.(type) {
+	case nil, bool, float64, string, LoxCallable, LoxInstance:
+		return v
+	case error:
+		return v.Error()
+	}
+
+	return toValue(reflect.ValueOf(v), name)
+}
+
+func toValue(v reflect.Value, name string) Value {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Func:
+		if v.IsNil() {
+			return nil
+		}
+		return goFunction(name, v)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toNamedValue(v.Interface(), name)
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	if v.Type().Implements(errorType) {
+		return v.Interface().(error).Error()
+	}
+
+	// structs and arrays which aren't already part of something addressable, such as the fields of a struct
+	// bound by pointer, are copied so that their fields and elements can be assigned to, and pointer methods called
+	if (v.Kind() == reflect.Struct || v.Kind() == reflect.Array) && !v.CanAddr() {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		v = ptr.Elem()
+	}
+
+	return &GoObject{value: v}
+}
+
+// fromValue converts a Lox value to a Go value of type t, for passing to a Go function or assigning to a field.
+func fromValue(value Value, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func:
+			return reflect.Zero(t), nil
+		}
+
+		return reflect.Value{}, fmt.Errorf("can't use nil as %s", t)
+	}
+
+	if obj, ok := value.(*GoObject); ok {
+		if obj.value.Type().AssignableTo(t) {
+			return obj.value, nil
+		}
+		// a struct can be passed where a pointer to it is wanted
+		if obj.value.CanAddr() && obj.value.Addr().Type().AssignableTo(t) {
+			return obj.value.Addr(), nil
+		}
+
+		return reflect.Value{}, fmt.Errorf("can't use %s as %s", obj.value.Type(), t)
+	}
+
+	if t == errorType {
+		if s, ok := value.(string); ok {
+			return reflect.ValueOf(errors.New(s)), nil
+		}
+	}
+
+	if t.Kind() == reflect.Interface {
+		v := reflect.ValueOf(value)
+		if v.Type().AssignableTo(t) {
+			return v.Convert(t), nil
+		}
+	}
+
+	switch v := value.(type) {
+	case bool:
+		if t.Kind() == reflect.Bool {
+			return reflect.ValueOf(v).Convert(t), nil
+		}
+	case string:
+		if t.Kind() == reflect.String {
+			return reflect.ValueOf(v).Convert(t), nil
+		}
+	case float64:
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return reflect.ValueOf(v).Convert(t), nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			// v is range checked before it's converted, as converting a float64 outside int64's range isn't defined
+			if v != math.Trunc(v) || v < -(1<<63) || v >= 1<<63 || reflect.Zero(t).OverflowInt(int64(v)) {
+				return reflect.Value{}, fmt.Errorf("can't use %v as %s", v, t)
+			}
+			return reflect.ValueOf(int64(v)).Convert(t), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if v != math.Trunc(v) || v < 0 || v >= 1<<64 || reflect.Zero(t).OverflowUint(uint64(v)) {
+				return reflect.Value{}, fmt.Errorf("can't use %v as %s", v, t)
+			}
+			return reflect.ValueOf(uint64(v)).Convert(t), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("can't use %s as %s", typeName(value), t)
+}
+
+// goFunction wraps a Go function so it can be called from Lox. Its arguments are converted with fromValue,
+// and its result with ToValue. If its last result is an error, a non-nil one is returned as a RuntimeError.
+func goFunction(name string, fn reflect.Value) *NativeFunction {
+	t := fn.Type()
+
+	params := t.NumIn()
+	if t.IsVariadic() {
+		params = Variadic
+	}
+
+	return NewNativeFunction(name, params, func(args Args) (Value, error) {
+		if t.IsVariadic() && args.Len() < t.NumIn()-1 {
+			return nil, args.expectIndex(t.NumIn() - 2)
+		}
+
+		in := make([]reflect.Value, args.Len())
+		for idx := range in {
+			paramType := t.In(minInt(idx, t.NumIn()-1))
+			if t.IsVariadic() && idx >= t.NumIn()-1 {
+				paramType = paramType.Elem()
+			}
+
+			arg, err := fromValue(args.Value(idx), paramType)
+			if err != nil {
+				return nil, NewRuntimeErrorWithCode(ErrArgumentType, args.paren, fmt.Sprintf("Argument %d of %s: %s.", idx+1, name, err))
+			}
+			in[idx] = arg
+		}
+
+		out, err := callGo(fn, in)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(out) > 0 && t.Out(len(out)-1) == errorType {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return nil, err
+			}
+			out = out[:len(out)-1]
+		}
+
+		switch len(out) {
+		case 0:
+			return nil, nil
+		case 1:
+			return toValue(out[0], name), nil
+		}
+
+		return nil, fmt.Errorf("returned %d values, but Lox functions can only return one", len(out))
+	})
+}
+
+// callGo calls fn, turning a panic into an error.
+func callGo(fn reflect.Value, in []reflect.Value) (out []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn.Call(in), nil
+}
+
+func (o *GoObject) get(name Token) (any, error) {
+	switch o.value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return o.getCollectionMember(name)
+	}
+
+	if field, ok := o.field(name.lexeme); ok {
+		return toValue(field, o.typeName()+"."+name.lexeme), nil
+	}
+
+	if method, ok := o.method(name.lexeme); ok {
+		return goFunction(o.typeName()+"."+name.lexeme, method), nil
+	}
+
+	return nil, NewRuntimeErrorWithCode(ErrUndefinedProperty, name, fmt.Sprintf("%s has no exported field or method '%s'.", o.typeName(), name.lexeme))
+}
+
+func (o *GoObject) set(name Token, value any) error {
+	field, ok := o.field(name.lexeme)
+	if !ok {
+		return NewRuntimeErrorWithCode(ErrUndefinedProperty, name, fmt.Sprintf("%s has no exported field '%s'.", o.typeName(), name.lexeme))
+	}
+
+	if !field.CanSet() {
+		return NewRuntimeErrorWithCode(ErrImmutable, name, fmt.Sprintf("Cannot assign to field '%s' of %s.", name.lexeme, o.typeName()))
+	}
+
+	v, err := fromValue(value, field.Type())
+	if err != nil {
+		return NewRuntimeErrorWithCode(ErrOperandType, name, fmt.Sprintf("Cannot assign to field '%s' of %s: %s.", name.lexeme, o.typeName(), err))
+	}
+
+	field.Set(v)
+	return nil
+}
+
+// field finds an exported field of a struct, or of the struct a pointer points to.
+func (o *GoObject) field(name string) (reflect.Value, bool) {
+	v := o.value
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	f, ok := v.Type().FieldByName(name)
+	if !ok || !f.IsExported() {
+		return reflect.Value{}, false
+	}
+
+	field, err := v.FieldByIndexErr(f.Index)
+	if err != nil {
+		// an embedded nil pointer is in the way
+		return reflect.Value{}, false
+	}
+
+	return field, true
+}
+
+// method finds an exported method, including those with pointer receivers when the value is addressable.
+func (o *GoObject) method(name string) (reflect.Value, bool) {
+	if o.value.CanAddr() {
+		if m := o.value.Addr().MethodByName(name); m.IsValid() {
+			return m, true
+		}
+	}
+
+	m := o.value.MethodByName(name)
+	return m, m.IsValid()
+}
+
+func (o *GoObject) getCollectionMember(name Token) (any, error) {
+	v := o.value
+	isMap := v.Kind() == reflect.Map
+	prefix := o.typeName() + "."
+
+	switch name.lexeme {
+	case "length":
+		return float64(v.Len()), nil
+	case "get":
+		return NewNativeFunction(prefix+"get", 1, func(args Args) (Value, error) {
+			key, err := o.key(args)
+			if err != nil {
+				return nil, err
+			}
+			if isMap {
+				return toValue(v.MapIndex(key), prefix+"get"), nil
+			}
+			return toValue(v.Index(int(key.Int())), prefix+"get"), nil
+		}), nil
+	case "set":
+		return NewNativeFunction(prefix+"set", 2, func(args Args) (Value, error) {
+			key, err := o.key(args)
+			if err != nil {
+				return nil, err
+			}
+
+			elemType := v.Type().Elem()
+			elem, err := fromValue(args.Value(1), elemType)
+			if err != nil {
+				return nil, NewRuntimeErrorWithCode(ErrArgumentType, args.paren, fmt.Sprintf("Argument 2 of %sset: %s.", prefix, err))
+			}
+
+			if isMap {
+				if !v.MapIndex(key).IsValid() {
+					if err := args.interpreter.allocate(args.paren, 1); err != nil {
+						return nil, err
+					}
+				}
+				v.SetMapIndex(key, elem)
+				return nil, nil
+			}
+
+			target := v.Index(int(key.Int()))
+			if !target.CanSet() {
+				return nil, NewRuntimeErrorWithCode(ErrImmutable, args.paren, fmt.Sprintf("Cannot assign to elements of %s.", o.typeName()))
+			}
+			target.Set(elem)
+			return nil, nil
+		}), nil
+	}
+
+	if isMap {
+		switch name.lexeme {
+		case "has":
+			return NewNativeFunction(prefix+"has", 1, func(args Args) (Value, error) {
+				key, err := o.key(args)
+				if err != nil {
+					return nil, err
+				}
+				return v.MapIndex(key).IsValid(), nil
+			}), nil
+		case "delete":
+			return NewNativeFunction(prefix+"delete", 1, func(args Args) (Value, error) {
+				key, err := o.key(args)
+				if err != nil {
+					return nil, err
+				}
+				v.SetMapIndex(key, reflect.Value{})
+				return nil, nil
+			}), nil
+		case "keys":
+			return NewNativeFunction(prefix+"keys", 0, func(args Args) (Value, error) {
+				if err := args.interpreter.allocate(args.paren, v.Len()); err != nil {
+					return nil, err
+				}
+				keys := reflect.MakeSlice(reflect.SliceOf(v.Type().Key()), 0, v.Len())
+				for _, key := range v.MapKeys() {
+					keys = reflect.Append(keys, key)
+				}
+				return toValue(keys, prefix+"keys"), nil
+			}), nil
+		}
+	}
+
+	return nil, NewRuntimeErrorWithCode(ErrUndefinedProperty, name, fmt.Sprintf("%s has no member '%s'.", o.typeName(), name.lexeme))
+}
+
+// key converts the first argument to a key of a map, or an index of a slice or array.
+func (o *GoObject) key(args Args) (reflect.Value, error) {
+	if o.value.Kind() == reflect.Map {
+		key, err := fromValue(args.Value(0), o.value.Type().Key())
+		if err != nil {
+			return reflect.Value{}, NewRuntimeErrorWithCode(ErrArgumentType, args.paren, fmt.Sprintf("Argument 1 of %s: %s.", args.function, err))
+		}
+		return key, nil
+	}
+
+	idx, err := args.Int(0)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if idx < 0 || idx >= o.value.Len() {
+		return reflect.Value{}, NewRuntimeError(args.paren, fmt.Sprintf("Index %d is out of range for %s of length %d.", idx, o.typeName(), o.value.Len()))
+	}
+
+	return reflect.ValueOf(idx), nil
+}
+
+// equals compares pointers, maps and slices by identity, and other values with ==.
+func (o *GoObject) equals(other *GoObject) bool {
+	if o.value.Type() != other.value.Type() {
+		return false
+	}
+
+	switch o.value.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		return o.value.Pointer() == other.value.Pointer()
+	}
+
+	if !o.value.Comparable() {
+		return false
+	}
+
+	return o.value.Equal(other.value)
+}
+
+func (o *GoObject) typeName() string {
+	return o.value.Type().String()
+}
+
+func (o *GoObject) toString() string {
+	if s, ok := o.value.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return "<go " + o.typeName() + ">"
+}
@@ -0,0 +1,209 @@
+package lox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+type testLimits struct {
+	Max int
+}
+
+type testConfig struct {
+	Name    string
+	Timeout float64
+	Retries int
+	Seed    uint64
+	Debug   bool
+	Tags    []string
+	Labels  map[string]string
+	Limits  testLimits
+	secret  string
+}
+
+func (c *testConfig) Describe(prefix string) string {
+	return fmt.Sprintf("%s%s (%d retries)", prefix, c.Name, c.Retries)
+}
+
+func (c *testConfig) SetRetries(n int) error {
+	if n < 0 {
+		return errors.New("retries can't be negative")
+	}
+	c.Retries = n
+	return nil
+}
+
+func (c testConfig) Sum(nums ...int) int {
+	total := 0
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+func (c *testConfig) Panic() {
+	panic("oh no")
+}
+
+func newTestConfig() *testConfig {
+	return &testConfig{
+		Name:    "api",
+		Timeout: 1.5,
+		Retries: 3,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"env": "prod"},
+		Limits:  testLimits{Max: 10},
+		secret:  "hunter2",
+	}
+}
+
+func TestEngine_BindStruct(t *testing.T) {
+	is := is2.New(t)
+
+	config := newTestConfig()
+
+	var stdout bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout))
+	engine.Bind("config", config)
+
+	err := runEngine(t, engine, `
+print config.Name;
+print config.Timeout + config.Retries;
+print config.Debug;
+config.Timeout = config.Timeout * 2;
+config.Debug = true;
+config.Limits.Max = 20;
+print config.Describe("service: ");
+config.SetRetries(5);
+print config.Sum(1, 2, 3);
+print config.Sum();
+`)
+	is.NoErr(err)
+	is.Equal(stdout.String(), "api\n4.5\nfalse\nservice: api (3 retries)\n6\n0\n")
+
+	// Lox and Go share the same struct
+	is.Equal(config.Timeout, 3.0)
+	is.Equal(config.Debug, true)
+	is.Equal(config.Retries, 5)
+	is.Equal(config.Limits.Max, 20)
+}
+
+func TestEngine_BindCollections(t *testing.T) {
+	is := is2.New(t)
+
+	config := newTestConfig()
+
+	var stdout bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout))
+	engine.Bind("config", config)
+
+	err := runEngine(t, engine, `
+var tags = config.Tags;
+print tags.length;
+print tags.get(1);
+tags.set(0, "z");
+
+var labels = config.Labels;
+print labels.get("env");
+print labels.get("missing");
+print labels.has("env");
+labels.set("team", "core");
+labels.delete("env");
+print labels.keys().get(0);
+`)
+	is.NoErr(err)
+	is.Equal(stdout.String(), "2\nb\nprod\nnil\ntrue\nteam\n")
+
+	is.Equal(config.Tags, []string{"z", "b"})
+	is.Equal(config.Labels, map[string]string{"team": "core"})
+}
+
+func TestEngine_BindFunctions(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout))
+	engine.Bind("join", strings.Join)
+	engine.Bind("split", strings.Split)
+	engine.Bind("itoa", func(n int) string { return fmt.Sprint(n) })
+	engine.Bind("find", func(name string) (*testConfig, error) {
+		if name == "api" {
+			return newTestConfig(), nil
+		}
+		return nil, nil
+	})
+
+	err := runEngine(t, engine, `
+print join(split("a,b,c", ","), "-");
+print itoa(42) + "!";
+print find("api").Name;
+print find("web");
+print find("api") == find("api");
+`)
+	is.NoErr(err)
+	is.Equal(stdout.String(), "a-b-c\n42!\napi\nnil\nfalse\n")
+}
+
+func TestEngine_BindErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		code    ErrorCode
+		message string
+	}{
+		{`config.SetRetries(-1);`, ErrRuntime, "*lox.testConfig.SetRetries: retries can't be negative"},
+		{`config.SetRetries(1.5);`, ErrArgumentType, "Argument 1 of *lox.testConfig.SetRetries: can't use 1.5 as int."},
+		{`config.SetRetries("1");`, ErrArgumentType, "Argument 1 of *lox.testConfig.SetRetries: can't use string as int."},
+		{`config.SetRetries();`, ErrArity, "Expected 1 arguments but got 0 arguments instead."},
+		{`config.Panic();`, ErrRuntime, "*lox.testConfig.Panic: panic: oh no"},
+		{`config.secret;`, ErrUndefinedProperty, "*lox.testConfig has no exported field or method 'secret'."},
+		{`config.secret = "x";`, ErrUndefinedProperty, "*lox.testConfig has no exported field 'secret'."},
+		{`config.Retries = "x";`, ErrOperandType, "Cannot assign to field 'Retries' of *lox.testConfig: can't use string as int."},
+		{`config.Retries = 9223372036854775808;`, ErrOperandType, "Cannot assign to field 'Retries' of *lox.testConfig: can't use 9.223372036854776e+18 as int."},
+		{`config.Retries = -10000000000000000000;`, ErrOperandType, "Cannot assign to field 'Retries' of *lox.testConfig: can't use -1e+19 as int."},
+		{`config.Seed = 18446744073709551616;`, ErrOperandType, "Cannot assign to field 'Seed' of *lox.testConfig: can't use 1.8446744073709552e+19 as uint64."},
+		{`config.Seed = -1;`, ErrOperandType, "Cannot assign to field 'Seed' of *lox.testConfig: can't use -1 as uint64."},
+		{`config.Tags.get(5);`, ErrRuntime, "Index 5 is out of range for []string of length 2."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			is := is2.New(t)
+
+			engine := NewEngine(WithStderr(&bytes.Buffer{}))
+			engine.Bind("config", newTestConfig())
+
+			err := runEngine(t, engine, tt.source)
+
+			var runtimeErr RuntimeError
+			is.True(errors.As(err, &runtimeErr))
+			is.Equal(runtimeErr.Code, tt.code)
+			is.Equal(runtimeErr.Error(), tt.message)
+		})
+	}
+}
+
+func TestToValue(t *testing.T) {
+	is := is2.New(t)
+
+	var nilConfig *testConfig
+	var nilErr error
+
+	is.Equal(ToValue(nil), nil)
+	is.Equal(ToValue(true), true)
+	is.Equal(ToValue("s"), "s")
+	is.Equal(ToValue(3), 3.0)
+	is.Equal(ToValue(uint8(3)), 3.0)
+	is.Equal(ToValue(float32(1.5)), 1.5)
+	is.Equal(ToValue(nilConfig), nil)
+	is.Equal(ToValue(nilErr), nil)
+	is.Equal(ToValue(errors.New("boom")), "boom")
+
+	obj, ok := ToValue(testConfig{Name: "x"}).(*GoObject)
+	is.True(ok)
+	is.Equal(obj.toString(), "<go lox.testConfig>")
+}
@@ -0,0 +1,206 @@
+package lox
+
+import (
+	"fmt"
+)
+
+// Limits caps the resources a program can use, so untrusted scripts can be run safely. Zero means no limit.
+type Limits struct {
+	// MaxSteps is the number of statements executed plus expressions evaluated
+	MaxSteps int
+	// MaxStringLength is the length in bytes of the longest string concatenation, or a native function, can build
+	MaxStringLength int
+	// MaxTotalAllocations is the number of values which can be stored in new variables, function parameters,
+	// enum members, record fields, new entries of bound Go maps, and the slices of their keys, over the whole
+	// run. It counts every one ever made, not how many there are at once, so a loop declaring a variable uses
+	// one more each time round even though the last is gone.
+	MaxTotalAllocations int
+}
+
+// Budget names one of the Limits.
+type Budget string
+
+const (
+	BudgetSteps        Budget = "step"
+	BudgetStringLength Budget = "string length"
+	BudgetAllocations  Budget = "allocation"
+)
+
+// BudgetExceededError is the RuntimeError a program stops with when it goes over one of its Limits.
+// errors.As matches it as a RuntimeError too.
+//
+// Unlike other runtime errors it can't be recovered from. Once a budget has run out, every later
+// step fails with the same error, even if something caught the first one.
+type BudgetExceededError struct {
+	RuntimeError
+	// Budget is the limit which was exceeded, and Limit its value
+	Budget Budget
+	Limit  int
+}
+
+func (e BudgetExceededError) As(target any) bool {
+	if t, ok := target.(*RuntimeError); ok {
+		*t = e.RuntimeError
+		return true
+	}
+
+	return false
+}
+
+func NewBudgetExceededError(token Token, budget Budget, limit int, msg string) BudgetExceededError {
+	err := NewRuntimeErrorWithCode(ErrBudgetExceeded, token, msg)
+	err.Help = fmt.Sprintf("the %s budget is %d", budget, limit)
+
+	return BudgetExceededError{
+		RuntimeError: err,
+		Budget:       budget,
+		Limit:        limit,
+	}
+}
+
+// budget counts the resources used by a program against its Limits. It's shared between the copies
+// of the Interpreter made while walking the tree.
+type budget struct {
+	limits      Limits
+	steps       int
+	allocations int
+	// lastToken is where the most recent step was, for errors in steps which don't have a token of their own
+	lastToken Token
+	// exceeded is the error from the first budget to run out, returned again by every later check
+	exceeded error
+}
+
+// WithLimits returns a copy of the Interpreter which stops the program when it uses up any of limits.
+// The resources used so far start from zero.
+func (i Interpreter) WithLimits(limits Limits) Interpreter {
+	if limits == (Limits{}) {
+		i.budget = nil
+	} else {
+		i.budget = &budget{limits: limits}
+	}
+
+	return i
+}
+
+// step counts a statement or expression against the step budget.
+func (i Interpreter) step(node any) error {
+	if i.budget == nil {
+		return nil
+	}
+	if i.budget.exceeded != nil {
+		return i.budget.exceeded
+	}
+
+	if token := tokenOf(node); token != (Token{}) {
+		i.budget.lastToken = token
+	}
+
+	i.budget.steps++
+	if max := i.budget.limits.MaxSteps; max > 0 && i.budget.steps > max {
+		return i.budget.exceed(NewBudgetExceededError(i.budget.lastToken, BudgetSteps, max, fmt.Sprintf("Step budget exceeded: ran more than %d steps.", max)))
+	}
+
+	return nil
+}
+
+// allocate counts n new values against the allocation budget, which never gets them back.
+// Errors are reported at token, or where the last step was if it's the zero Token.
+func (i Interpreter) allocate(token Token, n int) error {
+	if i.budget == nil {
+		return nil
+	}
+	if i.budget.exceeded != nil {
+		return i.budget.exceeded
+	}
+	if token == (Token{}) {
+		token = i.budget.lastToken
+	}
+
+	i.budget.allocations += n
+	if max := i.budget.limits.MaxTotalAllocations; max > 0 && i.budget.allocations > max {
+		return i.budget.exceed(NewBudgetExceededError(token, BudgetAllocations, max, fmt.Sprintf("Allocation budget exceeded: stored more than %d values in total.", max)))
+	}
+
+	return nil
+}
+
+// checkStringLength checks that concatenating left and right won't build a string over the string length budget.
+func (i Interpreter) checkStringLength(operator Token, left any, right any) error {
+	if i.budget == nil {
+		return nil
+	}
+	if i.budget.exceeded != nil {
+		return i.budget.exceeded
+	}
+
+	leftStr, ok := left.(string)
+	if !ok {
+		return nil
+	}
+	rightStr, ok := right.(string)
+	if !ok {
+		return nil
+	}
+
+	length := len(leftStr) + len(rightStr)
+	if max := i.budget.limits.MaxStringLength; max > 0 && length > max {
+		return i.budget.exceed(NewBudgetExceededError(operator, BudgetStringLength, max, fmt.Sprintf("String length budget exceeded: a string of %d bytes is longer than %d.", length, max)))
+	}
+
+	return nil
+}
+
+func (b *budget) exceed(err BudgetExceededError) error {
+	b.exceeded = err
+	return err
+}
+
+// tokenOf finds a token to report an error in a statement or expression at.
+func tokenOf(node any) Token {
+	switch n := node.(type) {
+	case Binary:
+		return n.operator
+	case Logical:
+		return n.operator
+	case Unary:
+		return n.operator
+	case Grouping:
+		return tokenOf(n.expression)
+	case Var:
+		return n.name
+	case Assign:
+		return n.name
+	case Call:
+		return n.paren
+	case Get:
+		return n.name
+	case Set:
+		return n.name
+	case StmtExpression:
+		return tokenOf(n.expression)
+	case StmtPrint:
+		return tokenOf(n.expression)
+	case StmtVar:
+		return n.name
+	case StmtIf:
+		return tokenOf(n.condition)
+	case StmtWhile:
+		return n.keyword
+	case StmtFunction:
+		return n.name
+	case StmtReturn:
+		return n.returnKeyword
+	case StmtAssert:
+		return n.keyword
+	case StmtEnum:
+		return n.name
+	case StmtRecord:
+		return n.name
+	case StmtBlock:
+		if len(n.statements) > 0 {
+			return tokenOf(n.statements[0])
+		}
+	}
+
+	return Token{}
+}
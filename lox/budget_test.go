@@ -0,0 +1,154 @@
+package lox
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestEngine_Limits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  Limits
+		source  string
+		budget  Budget
+		message string
+	}{
+		{
+			name:    "steps",
+			limits:  Limits{MaxSteps: 1000},
+			source:  `while (true) {}`,
+			budget:  BudgetSteps,
+			message: "Step budget exceeded: ran more than 1000 steps.",
+		},
+		{
+			name:    "steps in a negated call",
+			limits:  Limits{MaxSteps: 1000},
+			source:  `fun f() { while (true) {} } print -f();`,
+			budget:  BudgetSteps,
+			message: "Step budget exceeded: ran more than 1000 steps.",
+		},
+		{
+			name:    "steps in a not of a call",
+			limits:  Limits{MaxSteps: 1000},
+			source:  `fun f() { while (true) {} } print !f();`,
+			budget:  BudgetSteps,
+			message: "Step budget exceeded: ran more than 1000 steps.",
+		},
+		{
+			name:    "string length",
+			limits:  Limits{MaxStringLength: 1024},
+			source:  `var s = "ab"; while (true) { s = s + s; }`,
+			budget:  BudgetStringLength,
+			message: "String length budget exceeded: a string of 2048 bytes is longer than 1024.",
+		},
+		{
+			name:    "allocations",
+			limits:  Limits{MaxTotalAllocations: 100},
+			source:  `fun count(n) { var next = n + 1; return count(next); } count(0);`,
+			budget:  BudgetAllocations,
+			message: "Allocation budget exceeded: stored more than 100 values in total.",
+		},
+		{
+			name:    "allocations are counted in total",
+			limits:  Limits{MaxTotalAllocations: 50},
+			source:  `var i = 0; while (i < 100) { var x = i; i = i + 1; }`,
+			budget:  BudgetAllocations,
+			message: "Allocation budget exceeded: stored more than 50 values in total.",
+		},
+		{
+			name:    "record allocations",
+			limits:  Limits{MaxTotalAllocations: 100},
+			source:  `record P(x, y); var p = P(0, 0); while (true) { p = p.with(x: p.x + 1); }`,
+			budget:  BudgetAllocations,
+			message: "Allocation budget exceeded: stored more than 100 values in total.",
+		},
+		{
+			name:    "bound map allocations",
+			limits:  Limits{MaxTotalAllocations: 50},
+			source:  `var i = 0; while (i < 100) { counts.set(i, i); i = i + 1; }`,
+			budget:  BudgetAllocations,
+			message: "Allocation budget exceeded: stored more than 50 values in total.",
+		},
+		{
+			name:    "native string length",
+			limits:  Limits{MaxStringLength: 1024},
+			source:  `print repeat("ab", 1000);`,
+			budget:  BudgetStringLength,
+			message: "String length budget exceeded: a string of 2000 bytes is longer than 1024.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			var stdout bytes.Buffer
+			engine := NewEngine(WithStdout(&stdout), WithStderr(&bytes.Buffer{}), WithLimits(tt.limits))
+			engine.Bind("counts", map[int]int{})
+			engine.Define("repeat", func(args Args) (Value, error) {
+				s, err := args.String(0)
+				if err != nil {
+					return nil, err
+				}
+				n, err := args.Int(1)
+				if err != nil {
+					return nil, err
+				}
+				return strings.Repeat(s, n), nil
+			})
+			err := runEngine(t, engine, tt.source)
+
+			// nothing carries on once the budget has run out
+			is.Equal(stdout.String(), "")
+
+			var budgetErr BudgetExceededError
+			is.True(errors.As(err, &budgetErr))
+			is.Equal(budgetErr.Budget, tt.budget)
+			is.Equal(budgetErr.Error(), tt.message)
+
+			// it is a RuntimeError too
+			var runtimeErr RuntimeError
+			is.True(errors.As(err, &runtimeErr))
+			is.Equal(runtimeErr.Code, ErrBudgetExceeded)
+			is.True(runtimeErr.Token != Token{})
+		})
+	}
+}
+
+func TestEngine_LimitsAreNotExceeded(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout), WithLimits(Limits{MaxSteps: 1000, MaxStringLength: 10, MaxTotalAllocations: 10}))
+
+	err := runEngine(t, engine, `var s = "hello"; print s + "!";`)
+	is.NoErr(err)
+	is.Equal(stdout.String(), "hello!\n")
+
+	// each Run gets a fresh budget
+	for n := 0; n < 100; n++ {
+		is.NoErr(runEngine(t, engine, `var i = 0; while (i < 10) { i = i + 1; }`))
+	}
+}
+
+func TestInterpreter_BudgetCannotBeRecovered(t *testing.T) {
+	is := is2.New(t)
+
+	interpreter := NewInterpreter(&Lox{Stderr: &bytes.Buffer{}}, NewGlobalEnvironment()).WithLimits(Limits{MaxSteps: 10})
+
+	var first error
+	for n := 0; n < 20 && first == nil; n++ {
+		_, first = interpreter.evaluate(Literal{value: 1.0})
+	}
+	is.True(first != nil)
+
+	// once the budget has run out, everything else fails with the same error
+	_, err := interpreter.evaluate(Literal{value: 1.0})
+	is.Equal(err, first)
+	is.Equal(interpreter.allocate(Token{}, 1), first)
+	is.Equal(interpreter.checkStringLength(Token{}, "a", "b"), first)
+}
@@ -0,0 +1,112 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CallFrame is a call which was in progress, as recorded in a RuntimeError's Traceback.
+type CallFrame struct {
+	// Function is the name of the function which was called
+	Function string
+	// CallSite is the closing paren of the call expression, see Token.Line and Token.Column
+	CallSite Token
+}
+
+// callStack holds the frames of the calls currently being run by an Interpreter, innermost last.
+type callStack struct {
+	frames []CallFrame
+}
+
+func (c *callStack) depth() int {
+	return len(c.frames)
+}
+
+func (c *callStack) push(frame CallFrame) {
+	c.frames = append(c.frames, frame)
+}
+
+func (c *callStack) pop() {
+	c.frames = c.frames[:len(c.frames)-1]
+}
+
+// replaceTop swaps the function of the innermost frame for a tail call, which runs in place of the call
+// that made it. The frame keeps its call site, which is still where its caller is.
+func (c *callStack) replaceTop(function string) {
+	c.frames[len(c.frames)-1].Function = function
+}
+
+func (c *callStack) snapshot() []CallFrame {
+	frames := make([]CallFrame, len(c.frames))
+	copy(frames, c.frames)
+	return frames
+}
+
+// callableName is the name used for fn in stack traces.
+func callableName(fn LoxCallable) string {
+	switch f := fn.(type) {
+	case LoxFunction:
+		return f.declaration.name.lexeme
+	case *LoxRecord:
+		return f.name
+	case recordWith:
+		return f.instance.record.name + "." + recordWithMethod
+	case *NativeFunction:
+		return f.name
+	}
+
+	return stringify(fn)
+}
+
+// maxRepeatedTraceLines is how many identical lines in a row are printed before the rest are summarised,
+// so deep recursion doesn't produce thousands of lines.
+const maxRepeatedTraceLines = 3
+
+// StackTrace formats the error's Traceback, innermost call last. It is empty when the
+// error happened outside of any function.
+//
+//	Traceback (most recent call last):
+//	  line 9, in <script>
+//	  line 5, in outer()
+//	  line 2, in inner()
+func (e RuntimeError) StackTrace() string {
+	if len(e.Traceback) == 0 {
+		return ""
+	}
+
+	// each frame's location is the call site of the frame inside it, or the error itself for the innermost one
+	var lines []string
+	lines = append(lines, traceLine(e.Traceback[0].CallSite, "<script>"))
+	for idx, frame := range e.Traceback {
+		location := e.Token
+		if idx+1 < len(e.Traceback) {
+			location = e.Traceback[idx+1].CallSite
+		}
+		lines = append(lines, traceLine(location, frame.Function+"()"))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Traceback (most recent call last):\n")
+
+	for idx := 0; idx < len(lines); {
+		repeats := 1
+		for idx+repeats < len(lines) && lines[idx+repeats] == lines[idx] {
+			repeats++
+		}
+
+		for r := 0; r < repeats && r < maxRepeatedTraceLines; r++ {
+			sb.WriteString(lines[idx])
+		}
+		if repeats > maxRepeatedTraceLines {
+			sb.WriteString(fmt.Sprintf("  [previous line repeated %d more times]\n", repeats-maxRepeatedTraceLines))
+		}
+
+		idx += repeats
+	}
+
+	return sb.String()
+}
+
+func traceLine(location Token, function string) string {
+	return fmt.Sprintf("  line %d, column %d, in %s\n", location.line, location.column, function)
+}
@@ -0,0 +1,107 @@
+package lox
+
+import (
+	"strings"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestRuntimeError_Traceback(t *testing.T) {
+	is := is2.New(t)
+
+	_, err := interpretSourceWithLox(t, &Lox{}, `fun inner(n) {
+  return n / 0;
+}
+fun outer(n) {
+  var result = inner(n);
+  return result;
+}
+outer(1);`)
+
+	runtimeErr, ok := err.(RuntimeError)
+	is.True(ok)
+
+	var functions []string
+	for _, frame := range runtimeErr.Traceback {
+		functions = append(functions, frame.Function)
+	}
+	is.Equal(functions, []string{"outer", "inner"})
+
+	is.Equal(runtimeErr.StackTrace(), ""+
+		"Traceback (most recent call last):\n"+
+		"  line 8, column 8, in <script>\n"+
+		"  line 5, column 23, in outer()\n"+
+		"  line 2, column 12, in inner()\n")
+}
+
+func TestRuntimeError_TracebackFollowsTailCalls(t *testing.T) {
+	is := is2.New(t)
+
+	_, err := interpretSourceWithLox(t, &Lox{}, `fun outer() {
+  var r = first();
+  return r;
+}
+fun first() {
+  return fail();
+}
+fun fail() {
+  return nil + 1;
+}
+outer();`)
+
+	runtimeErr, ok := err.(RuntimeError)
+	is.True(ok)
+	is.Equal(len(runtimeErr.Traceback), 2)
+	is.Equal(runtimeErr.Traceback[1].Function, "fail")
+
+	// first() has returned into fail(), which is reported where first() was called from
+	is.Equal(runtimeErr.StackTrace(), ""+
+		"Traceback (most recent call last):\n"+
+		"  line 11, column 7, in <script>\n"+
+		"  line 2, column 17, in outer()\n"+
+		"  line 9, column 14, in fail()\n")
+}
+
+func TestRuntimeError_TracebackFollowsTailCallsAtTopLevel(t *testing.T) {
+	is := is2.New(t)
+
+	_, err := interpretSourceWithLox(t, &Lox{}, `fun a() {
+  return b();
+}
+fun b() {
+  return nil + 1;
+}
+a();`)
+
+	runtimeErr, ok := err.(RuntimeError)
+	is.True(ok)
+	is.Equal(runtimeErr.StackTrace(), ""+
+		"Traceback (most recent call last):\n"+
+		"  line 7, column 3, in <script>\n"+
+		"  line 5, column 14, in b()\n")
+}
+
+func TestRuntimeError_TracebackCollapsesRecursion(t *testing.T) {
+	is := is2.New(t)
+
+	_, err := interpretSourceWithLox(t, &Lox{MaxCallDepth: 100}, `
+		fun runaway() { return 1 + runaway(); }
+		runaway();
+	`)
+
+	runtimeErr, ok := err.(RuntimeError)
+	is.True(ok)
+	is.Equal(len(runtimeErr.Traceback), 100)
+	is.True(strings.Contains(runtimeErr.StackTrace(), "[previous line repeated 97 more times]"))
+}
+
+func TestRuntimeError_NoTracebackAtTopLevel(t *testing.T) {
+	is := is2.New(t)
+
+	_, err := interpretSource(t, "var x = 1 / 0;")
+
+	runtimeErr, ok := err.(RuntimeError)
+	is.True(ok)
+	is.Equal(runtimeErr.StackTrace(), "")
+}
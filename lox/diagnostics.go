@@ -0,0 +1,320 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrorCode identifies a kind of diagnostic. Codes are stable, so they can be searched for and documented.
+type ErrorCode string
+
+const (
+	// scanner errors
+
+	ErrUnexpectedCharacter  ErrorCode = "E0001" // a character which can't start any token
+	ErrInvalidEncoding      ErrorCode = "E0002" // source which isn't valid UTF-8
+	ErrUnterminatedString   ErrorCode = "E0003" // a string literal missing its closing quote
+	ErrUnterminatedComment  ErrorCode = "E0004" // a block comment missing its closing */
+	ErrInvalidNumberLiteral ErrorCode = "E0005" // a number literal which can't be parsed
+
+	// parser errors
+
+	ErrSyntax                  ErrorCode = "E0010" // a token which isn't allowed where it appears
+	ErrInvalidAssignmentTarget ErrorCode = "E0011" // assigning to something which isn't a variable or field
+	ErrReturnOutsideFunction   ErrorCode = "E0013" // a return statement in top-level code
+	ErrDuplicateName           ErrorCode = "E0014" // the same name declared twice in one enum, record or call
+	ErrTooManyArguments        ErrorCode = "E0015" // more than 255 parameters or arguments
+
+	// runtime errors
+
+	ErrUndefinedVariable ErrorCode = "E0012" // a variable which hasn't been declared
+	ErrRuntime           ErrorCode = "E0020" // a runtime error which doesn't have a more specific code
+	ErrOperandType       ErrorCode = "E0021" // an operator applied to values of the wrong type
+	ErrDivideByZero      ErrorCode = "E0022" // division by zero
+	ErrNotCallable       ErrorCode = "E0023" // calling a value which isn't a function, record or class
+	ErrArity             ErrorCode = "E0024" // calling a function with the wrong arguments
+	ErrUndefinedProperty ErrorCode = "E0025" // accessing a property which doesn't exist
+	ErrImmutable         ErrorCode = "E0026" // assigning to a read-only property
+	ErrAssertionFailed   ErrorCode = "E0027" // an assert statement whose condition was falsey
+	ErrStackOverflow     ErrorCode = "E0028" // calls nested deeper than the interpreter allows
+	ErrArgumentType      ErrorCode = "E0029" // a native function called with an argument of the wrong type
+	ErrCancelled         ErrorCode = "E0030" // a program stopped because its context was cancelled or timed out
+	ErrBudgetExceeded    ErrorCode = "E0031" // a program which went over one of its Limits
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return ""
+	}
+}
+
+// Diagnostic is a problem found in a Lox program, while scanning, parsing or running it.
+type Diagnostic struct {
+	Severity Severity
+	Code     ErrorCode
+	Message  string
+
+	// File is the name of the file the program came from, if it has one
+	File string
+
+	// Line and Column are where the problem starts, and EndLine and EndColumn where it ends (exclusive).
+	// Start and End are the byte offsets of the same span.
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+	Start     int
+	End       int
+
+	Notes []string
+	Help  string
+
+	// Err is the error the diagnostic describes: ParseError for problems found by Compile,
+	// or the RuntimeError a program stopped with
+	Err error
+}
+
+// NewDiagnostic creates an error Diagnostic spanning token.
+func NewDiagnostic(code ErrorCode, token Token, message string) Diagnostic {
+	endLine := token.line + strings.Count(token.lexeme, "\n")
+	endColumn := token.column + utf8.RuneCountInString(token.lexeme)
+	if idx := strings.LastIndexByte(token.lexeme, '\n'); idx != -1 {
+		endColumn = 1 + utf8.RuneCountInString(token.lexeme[idx+1:])
+	}
+
+	return Diagnostic{
+		Severity:  SeverityError,
+		Code:      code,
+		Message:   message,
+		Line:      token.line,
+		Column:    token.column,
+		EndLine:   endLine,
+		EndColumn: endColumn,
+		Start:     token.start,
+		End:       token.end,
+	}
+}
+
+// location is where the diagnostic is, as file:line:column. The parts it doesn't have are left out,
+// such as the position of an error which didn't happen at any token.
+func (d Diagnostic) location() string {
+	var parts []string
+	if d.File != "" {
+		parts = append(parts, d.File)
+	}
+	if d.Line >= 1 {
+		parts = append(parts, strconv.Itoa(d.Line))
+		if d.Column >= 1 {
+			parts = append(parts, strconv.Itoa(d.Column))
+		}
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// Diagnostics is the error returned by Engine.Compile and Engine.Run: every problem found, in the order they were found.
+// errors.Is and errors.As look through it at each Diagnostic's Err, so errors.Is(err, ParseError) tells whether
+// a program failed to compile, and errors.As(err, &runtimeErr) gets the RuntimeError a program stopped with.
+type Diagnostics []Diagnostic
+
+func (d Diagnostics) Error() string {
+	var sb strings.Builder
+
+	for i, diagnostic := range d {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if location := diagnostic.location(); location != "" {
+			sb.WriteString(location + ": ")
+		}
+		sb.WriteString(fmt.Sprintf("%s[%s]: %s", diagnostic.Severity, diagnostic.Code, diagnostic.Message))
+	}
+
+	return sb.String()
+}
+
+func (d Diagnostics) Unwrap() []error {
+	var errs []error
+
+	for _, diagnostic := range d {
+		if diagnostic.Err != nil {
+			errs = append(errs, diagnostic.Err)
+		}
+	}
+
+	return errs
+}
+
+// DiagnosticRenderer writes diagnostics in a human-readable form, e.g.
+//
+//	error[E0012]: Undefined variable 'cuont'.
+//	 --> 3:7
+//	  |
+//	3 | print cuont;
+//	  |       ^^^^^
+//	  = help: did you mean 'count'?
+//
+// The source snippet is left out when Source is empty.
+type DiagnosticRenderer struct {
+	Writer io.Writer
+	Source string
+	Color  bool
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[1;31m"
+	ansiYellow = "\x1b[1;33m"
+	ansiBlue   = "\x1b[1;34m"
+)
+
+// NewDiagnosticRenderer creates a renderer which uses color if w is a terminal.
+func NewDiagnosticRenderer(w io.Writer, source string) DiagnosticRenderer {
+	return DiagnosticRenderer{
+		Writer: w,
+		Source: source,
+		Color:  useColor(w),
+	}
+}
+
+func (r DiagnosticRenderer) Render(d Diagnostic) {
+	var sb strings.Builder
+
+	severityColor := ansiRed
+	if d.Severity == SeverityWarning {
+		severityColor = ansiYellow
+	}
+
+	sb.WriteString(r.paint(severityColor, fmt.Sprintf("%s[%s]", d.Severity, d.Code)))
+	sb.WriteString(r.paint(ansiBold, ": "+d.Message))
+	sb.WriteString("\n")
+
+	lineNumber := strconv.Itoa(d.Line)
+	gutter := strings.Repeat(" ", len(lineNumber))
+
+	if location := d.location(); location != "" {
+		sb.WriteString(fmt.Sprintf("%s%s %s\n", gutter, r.paint(ansiBlue, "-->"), location))
+	}
+
+	// there's nothing to underline without a line and column
+	if line, ok := r.sourceLine(d.Start); ok && d.Line >= 1 && d.Column >= 1 {
+		underline := strings.Repeat(" ", d.Column-1) + strings.Repeat("^", r.underlineWidth(d, line))
+
+		sb.WriteString(fmt.Sprintf("%s %s\n", gutter, r.paint(ansiBlue, "|")))
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", r.paint(ansiBlue, lineNumber), r.paint(ansiBlue, "|"), line))
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", gutter, r.paint(ansiBlue, "|"), r.paint(severityColor, underline)))
+	}
+
+	for _, note := range d.Notes {
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", gutter, r.paint(ansiBlue, "="), r.paint(ansiBold, "note: ")+note))
+	}
+
+	if d.Help != "" {
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", gutter, r.paint(ansiBlue, "="), r.paint(ansiBold, "help: ")+d.Help))
+	}
+
+	_, _ = io.WriteString(r.Writer, sb.String())
+}
+
+// RenderError writes every Diagnostic in err, if it's Diagnostics, followed by the stack trace of any RuntimeError.
+// Other errors are written on a line of their own.
+func (r DiagnosticRenderer) RenderError(err error) {
+	var diagnostics Diagnostics
+	if !errors.As(err, &diagnostics) {
+		_, _ = fmt.Fprintf(r.Writer, "%s: %s\n", r.paint(ansiRed, SeverityError.String()), err)
+		return
+	}
+
+	for _, d := range diagnostics {
+		r.Render(d)
+
+		var runtimeErr RuntimeError
+		if d.Err != nil && errors.As(d.Err, &runtimeErr) {
+			_, _ = io.WriteString(r.Writer, runtimeErr.StackTrace())
+		}
+	}
+}
+
+// sourceLine finds the full line of source containing the byte at offset.
+func (r DiagnosticRenderer) sourceLine(offset int) (string, bool) {
+	if r.Source == "" || offset < 0 || offset > len(r.Source) {
+		return "", false
+	}
+
+	start := strings.LastIndexByte(r.Source[:offset], '\n') + 1
+	end := strings.IndexByte(r.Source[offset:], '\n')
+	if end == -1 {
+		end = len(r.Source)
+	} else {
+		end += offset
+	}
+
+	return strings.TrimRight(r.Source[start:end], "\r"), true
+}
+
+// underlineWidth is the number of columns to underline, clipped to the end of the first line of the span.
+func (r DiagnosticRenderer) underlineWidth(d Diagnostic, line string) int {
+	if d.End <= d.Start || d.End > len(r.Source) {
+		return 1
+	}
+
+	span := r.Source[d.Start:d.End]
+	if idx := strings.IndexByte(span, '\n'); idx != -1 {
+		span = span[:idx]
+	}
+
+	width := utf8.RuneCountInString(span)
+	if remaining := utf8.RuneCountInString(line) - (d.Column - 1); width > remaining {
+		width = remaining
+	}
+	if width < 1 {
+		width = 1
+	}
+
+	return width
+}
+
+func (r DiagnosticRenderer) paint(color string, s string) string {
+	if !r.Color {
+		return s
+	}
+
+	return color + s + ansiReset
+}
+
+// useColor reports whether w is a terminal, following the NO_COLOR convention to opt out.
+func useColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,175 @@
+package lox
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestDiagnosticRenderer_Render(t *testing.T) {
+	is := is2.New(t)
+
+	source := "var count = 1;\nprint cuont + 1;\n"
+	var out bytes.Buffer
+	renderer := DiagnosticRenderer{
+		Writer: &out,
+		Source: source,
+	}
+
+	renderer.Render(Diagnostic{
+		Severity: SeverityError,
+		Code:     ErrUndefinedVariable,
+		Message:  "Undefined variable 'cuont'.",
+		Line:     2,
+		Column:   7,
+		Start:    21,
+		End:      26,
+		Notes:    []string{"variables must be declared before use"},
+		Help:     "did you mean 'count'?",
+	})
+
+	is.Equal(out.String(), ""+
+		"error[E0012]: Undefined variable 'cuont'.\n"+
+		" --> 2:7\n"+
+		"  |\n"+
+		"2 | print cuont + 1;\n"+
+		"  |       ^^^^^\n"+
+		"  = note: variables must be declared before use\n"+
+		"  = help: did you mean 'count'?\n")
+}
+
+func TestDiagnosticRenderer_RenderClipsMultiLineSpans(t *testing.T) {
+	is := is2.New(t)
+
+	source := "var s = \"abc\ndef"
+	var out bytes.Buffer
+	renderer := DiagnosticRenderer{
+		Writer: &out,
+		Source: source,
+		Color:  false,
+	}
+
+	renderer.Render(Diagnostic{
+		Severity: SeverityError,
+		Code:     ErrUnterminatedString,
+		Message:  "Unterminated string.",
+		Line:     1,
+		Column:   9,
+		Start:    8,
+		End:      len(source),
+	})
+
+	is.Equal(out.String(), ""+
+		"error[E0003]: Unterminated string.\n"+
+		" --> 1:9\n"+
+		"  |\n"+
+		"1 | var s = \"abc\n"+
+		"  |         ^^^^\n")
+}
+
+func TestDiagnosticRenderer_RenderWithoutSource(t *testing.T) {
+	is := is2.New(t)
+
+	var out bytes.Buffer
+	renderer := DiagnosticRenderer{Writer: &out}
+
+	renderer.Render(NewDiagnostic(ErrDivideByZero, Token{tokenType: SLASH, lexeme: "/", line: 3, column: 4}, "Cannot divide by zero"))
+
+	is.Equal(out.String(), "error[E0022]: Cannot divide by zero\n --> 3:4\n")
+}
+
+func TestNewDiagnostic_Span(t *testing.T) {
+	tests := []struct {
+		name          string
+		lexeme        string
+		wantEndLine   int
+		wantEndColumn int
+	}{
+		{"single line", "count", 3, 10},
+		{"multi-byte runes", "\"héllo\"", 3, 12},
+		{"multiple lines", "\"\"\"\n  abc\n  de\"\"\"", 5, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			d := NewDiagnostic(ErrRuntime, Token{lexeme: tt.lexeme, line: 3, column: 5}, "message")
+			is.Equal(d.EndLine, tt.wantEndLine)
+			is.Equal(d.EndColumn, tt.wantEndColumn)
+		})
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	is := is2.New(t)
+
+	runtimeErr := NewRuntimeError_DivideByZero(Token{line: 2, column: 3})
+	diagnostics := Diagnostics{
+		{Severity: SeverityError, Code: ErrSyntax, Message: "Expect expression.", File: "main.lox", Line: 1, Column: 10, Err: ParseError},
+		{Severity: SeverityError, Code: ErrDivideByZero, Message: "Cannot divide by zero", Line: 2, Column: 3, Err: runtimeErr},
+	}
+
+	is.Equal(diagnostics.Error(), ""+
+		"main.lox:1:10: error[E0010]: Expect expression.\n"+
+		"2:3: error[E0022]: Cannot divide by zero")
+	is.Equal(Diagnostics{{Severity: SeverityError, Code: ErrRuntime, Message: "oops"}}.Error(), "error[E0020]: oops")
+
+	var err error = diagnostics
+	is.True(errors.Is(err, ParseError))
+
+	var got RuntimeError
+	is.True(errors.As(err, &got))
+	is.Equal(got.Code, ErrDivideByZero)
+}
+
+func TestDiagnosticRenderer_RenderError(t *testing.T) {
+	is := is2.New(t)
+
+	var out bytes.Buffer
+	renderer := DiagnosticRenderer{Writer: &out}
+
+	renderer.RenderError(errors.New("reading source: broken pipe"))
+	is.Equal(out.String(), "error: reading source: broken pipe\n")
+}
+
+func TestDiagnosticRenderer_RenderWithoutPosition(t *testing.T) {
+	tests := []struct {
+		name       string
+		diagnostic Diagnostic
+		want       string
+	}{
+		{
+			name:       "no position",
+			diagnostic: NewDiagnostic(ErrRuntime, Token{}, "something went wrong"),
+			want:       "error[E0020]: something went wrong\n",
+		},
+		{
+			name:       "file without a position",
+			diagnostic: Diagnostic{Severity: SeverityError, Code: ErrRuntime, Message: "something went wrong", File: "main.lox"},
+			want:       "error[E0020]: something went wrong\n --> main.lox\n",
+		},
+		{
+			name:       "line without a column",
+			diagnostic: Diagnostic{Severity: SeverityError, Code: ErrRuntime, Message: "something went wrong", Line: 2},
+			want:       "error[E0020]: something went wrong\n --> 2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			var out bytes.Buffer
+			renderer := DiagnosticRenderer{
+				Writer: &out,
+				Source: "print 1;\nprint 2;\n",
+			}
+
+			renderer.Render(tt.diagnostic)
+			is.Equal(out.String(), tt.want)
+		})
+	}
+}
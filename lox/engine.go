@@ -0,0 +1,295 @@
+package lox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Engine compiles and runs Lox programs, for embedding the interpreter in Go programs:
+//
+//	engine := lox.NewEngine(lox.WithStdout(&out))
+//	program, err := engine.Compile(`print "hello";`)
+//	if err != nil {
+//		return err
+//	}
+//	err = engine.Run(ctx, program)
+//
+// Programs run by one Engine share its global environment, so variables and functions
+// defined by one are visible to the ones run after it. An Engine isn't safe for concurrent use.
+type Engine struct {
+	lox     *Lox
+	globals *Environment
+	limits  Limits
+	// historyFile is where RunPrompt saves the lines typed into it
+	historyFile string
+}
+
+// Option configures an Engine.
+type Option func(e *Engine)
+
+// WithStdin sets where RunPrompt reads input from, instead of os.Stdin.
+func WithStdin(r io.Reader) Option {
+	return func(e *Engine) {
+		e.lox.Stdin = r
+	}
+}
+
+// WithStdout sets where print statements write, instead of os.Stdout.
+func WithStdout(w io.Writer) Option {
+	return func(e *Engine) {
+		e.lox.Stdout = w
+	}
+}
+
+// WithStderr sets where RunPrompt reports errors, instead of os.Stderr.
+func WithStderr(w io.Writer) Option {
+	return func(e *Engine) {
+		e.lox.Stderr = w
+	}
+}
+
+// WithMaxCallDepth limits how deeply Lox function calls can nest, instead of DefaultMaxCallDepth.
+// It panics unless depth is between 1 and MaxCallDepthLimit.
+func WithMaxCallDepth(depth int) Option {
+	if depth < 1 || depth > MaxCallDepthLimit {
+		panic(fmt.Sprintf("lox: WithMaxCallDepth(%d): depth must be between 1 and %d", depth, MaxCallDepthLimit))
+	}
+
+	return func(e *Engine) {
+		e.lox.MaxCallDepth = depth
+	}
+}
+
+// WithLimits caps the resources each program run by the Engine can use, see Limits.
+func WithLimits(limits Limits) Option {
+	return func(e *Engine) {
+		e.limits = limits
+	}
+}
+
+// WithHistoryFile sets where RunPrompt saves the lines typed into it, instead of DefaultHistoryFile.
+// History isn't saved if path is "".
+func WithHistoryFile(path string) Option {
+	return func(e *Engine) {
+		e.historyFile = path
+	}
+}
+
+// WithGlobals defines global variables, available to every program the Engine runs.
+// The values are converted with ToValue, so they can be any Go values.
+func WithGlobals(globals map[string]any) Option {
+	return func(e *Engine) {
+		for name, value := range globals {
+			e.Bind(name, value)
+		}
+	}
+}
+
+func NewEngine(options ...Option) *Engine {
+	e := &Engine{
+		lox:         &Lox{},
+		globals:     NewGlobalEnvironment(),
+		historyFile: DefaultHistoryFile(),
+	}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// Define makes fn available to Lox code as a global function called name. It can be called with
+// any number of arguments, so fn should check them, e.g. with Args.Expect:
+//
+//	engine.Define("upper", func(args lox.Args) (lox.Value, error) {
+//		if err := args.Expect(1); err != nil {
+//			return nil, err
+//		}
+//		s, err := args.String(0)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return strings.ToUpper(s), nil
+//	})
+func (e *Engine) Define(name string, fn NativeFunc) {
+	e.globals.Define(name, NewNativeFunction(name, Variadic, fn))
+}
+
+// DefineModule makes a group of native functions available to Lox code as a global called name,
+// so that they're called as name.function(...).
+func (e *Engine) DefineModule(name string, module Module) {
+	e.globals.Define(name, NewLoxModule(name, module))
+}
+
+// Bind makes a Go value available to Lox code as a global called name. It is converted with ToValue,
+// so structs, and pointers to them, can have their exported fields and methods used from Lox.
+func (e *Engine) Bind(name string, value any) {
+	e.globals.Define(name, toNamedValue(value, name))
+}
+
+// Program is compiled Lox code, ready to be run by the Engine which compiled it.
+type Program struct {
+	file       string
+	source     string
+	statements []Stmt
+}
+
+// Compile scans and parses source. Any syntax errors are returned as Diagnostics.
+func (e *Engine) Compile(source string) (*Program, error) {
+	return e.CompileSource("", source)
+}
+
+// CompileSource is Compile for source read from a file called name, which diagnostics refer to.
+func (e *Engine) CompileSource(name string, source string) (*Program, error) {
+	e.lox.source = source
+	e.lox.file = name
+	e.lox.diagnostics = nil
+	e.lox.hadError = false
+
+	scanner := Scanner{
+		lox:    e.lox,
+		source: source,
+		line:   1,
+	}
+	tokens, err := scanner.scanTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := Parser{
+		Lox:    e.lox,
+		Tokens: tokens,
+		Source: source,
+	}
+	statements, err := parser.Parse()
+
+	// the scanner reports its errors without stopping, so those are included too
+	if e.lox.hadError {
+		return nil, Diagnostics(e.lox.diagnostics)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{
+		file:       name,
+		source:     source,
+		statements: statements,
+	}, nil
+}
+
+// Run runs program in the Engine's global environment. It stops at the first runtime error,
+// which is returned as Diagnostics wrapping the RuntimeError.
+//
+// If ctx is cancelled, or its deadline passes, the program stops at the next loop iteration, function call
+// or top-level statement. The error returned then matches ctx.Err() with errors.Is, so a timeout can be
+// told apart with errors.Is(err, context.DeadlineExceeded).
+func (e *Engine) Run(ctx context.Context, program *Program) error {
+	return e.run(ctx, e.interpreter(), program)
+}
+
+// run runs program with interpreter, which the REPL keeps between lines.
+func (e *Engine) run(ctx context.Context, interpreter Interpreter, program *Program) error {
+	e.lox.source = program.source
+	e.lox.file = program.file
+	e.lox.hadRuntimeError = false
+
+	interpreter = interpreter.WithContext(ctx)
+
+	position := programStart
+	for _, stmt := range program.statements {
+		position = stoppedAt(stmt, position)
+		if err := ctx.Err(); err != nil {
+			return e.lox.runtimeDiagnostics(NewRuntimeError_Cancelled(position, err))
+		}
+
+		err := interpreter.InterpretStatements([]Stmt{stmt})
+		if err != nil {
+			return e.lox.runtimeDiagnostics(err)
+		}
+	}
+
+	return nil
+}
+
+// Render writes err, as returned by Compile or Run, to the Engine's Stderr. Diagnostics are shown with
+// snippets of the source of the program last compiled or run.
+func (e *Engine) Render(err error) {
+	e.lox.render(err)
+}
+
+// RunStream runs a program while it is being read from r, executing each top-level declaration
+// as soon as it has been parsed. After an error the rest of the program is still parsed, to
+// report any further syntax errors, but nothing more is run. Errors are returned as Diagnostics.
+func (e *Engine) RunStream(ctx context.Context, r io.Reader) error {
+	e.lox.source = ""
+	e.lox.file = ""
+	e.lox.diagnostics = nil
+	e.lox.hadError = false
+	e.lox.hadRuntimeError = false
+
+	scanner := NewScanner(e.lox, r)
+	parser := NewStreamingParser(e.lox, scanner)
+	interpreter := e.interpreter().WithContext(ctx)
+
+	var runtimeErr error
+	position := programStart
+	for {
+		stmt, err := parser.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, ParseError) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("RunStream error, reading source: %w", err)
+		}
+
+		if e.lox.hadError || runtimeErr != nil {
+			continue
+		}
+
+		position = stoppedAt(stmt, position)
+		if err := ctx.Err(); err != nil {
+			return e.lox.runtimeDiagnostics(NewRuntimeError_Cancelled(position, err))
+		}
+
+		runtimeErr = interpreter.InterpretStatements([]Stmt{stmt})
+	}
+
+	if e.lox.hadError {
+		return Diagnostics(e.lox.diagnostics)
+	}
+	if runtimeErr != nil {
+		return e.lox.runtimeDiagnostics(runtimeErr)
+	}
+
+	return nil
+}
+
+// programStart is where a program stopped before its first statement is reported, if the statement
+// has nothing of its own to point at.
+var programStart = Token{line: 1, column: 1}
+
+// stoppedAt is where a program stopped by its context before running stmt is reported: at stmt,
+// or if it has no token to point at, like print "done";, at previous, the last statement which had.
+func stoppedAt(stmt Stmt, previous Token) Token {
+	if token := tokenOf(stmt); token != (Token{}) {
+		return token
+	}
+
+	return previous
+}
+
+func (e *Engine) interpreter() Interpreter {
+	interpreter := NewInterpreter(e.lox, e.globals).WithLimits(e.limits)
+	if e.lox.MaxCallDepth > 0 {
+		interpreter.MaxCallDepth = e.lox.MaxCallDepth
+	}
+
+	return interpreter
+}
@@ -0,0 +1,41 @@
+package lox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/abaayd01/golox/lox"
+	is2 "github.com/matryer/is"
+)
+
+func TestRuntimeError_PositionsOutsideThePackage(t *testing.T) {
+	is := is2.New(t)
+
+	engine := lox.NewEngine(lox.WithStdout(io.Discard))
+	program, err := engine.Compile(`fun inner() {
+  return 1 / 0;
+}
+fun outer() {
+  var result = inner();
+  return result;
+}
+outer();`)
+	is.NoErr(err)
+
+	err = engine.Run(context.Background(), program)
+
+	var runtimeErr lox.RuntimeError
+	is.True(errors.As(err, &runtimeErr))
+	is.Equal(runtimeErr.Token.Lexeme(), "/")
+	is.Equal(runtimeErr.Token.Line(), 2)
+	is.Equal(runtimeErr.Token.Column(), 12)
+
+	is.Equal(len(runtimeErr.Traceback), 2)
+	is.Equal(runtimeErr.Traceback[0].Function, "outer")
+	is.Equal(runtimeErr.Traceback[0].CallSite.Line(), 8)
+	is.Equal(runtimeErr.Traceback[1].Function, "inner")
+	is.Equal(runtimeErr.Traceback[1].CallSite.Line(), 5)
+	is.Equal(runtimeErr.Traceback[1].CallSite.Column(), 22)
+}
@@ -0,0 +1,337 @@
+package lox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestEngine_CompileAndRun(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout))
+
+	program, err := engine.Compile(`
+fun greet(name) {
+	return "hello, " + name;
+}
+print greet("world");
+`)
+	is.NoErr(err)
+
+	err = engine.Run(context.Background(), program)
+	is.NoErr(err)
+	is.Equal(stdout.String(), "hello, world\n")
+}
+
+func TestEngine_Globals(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout bytes.Buffer
+	engine := NewEngine(
+		WithStdout(&stdout),
+		WithGlobals(map[string]any{"limit": 3.0, "name": "golox"}),
+	)
+
+	first, err := engine.Compile(`var count = limit * 2; print name;`)
+	is.NoErr(err)
+	is.NoErr(engine.Run(context.Background(), first))
+
+	// globals defined by one program are visible to the next
+	second, err := engine.Compile(`print count;`)
+	is.NoErr(err)
+	is.NoErr(engine.Run(context.Background(), second))
+
+	is.Equal(stdout.String(), "golox\n6\n")
+}
+
+func TestEngine_CompileError(t *testing.T) {
+	is := is2.New(t)
+
+	var stderr bytes.Buffer
+	engine := NewEngine(WithStderr(&stderr))
+
+	program, err := engine.CompileSource("main.lox", "print 1 +;\nvar @x = 1;")
+	is.True(program == nil)
+	is.True(errors.Is(err, ParseError))
+
+	var diagnostics Diagnostics
+	is.True(errors.As(err, &diagnostics))
+	is.Equal(len(diagnostics), 2)
+	// the whole source is scanned before it's parsed
+	is.Equal(diagnostics[0].Code, ErrUnexpectedCharacter)
+	is.Equal(diagnostics[1].Code, ErrSyntax)
+	is.Equal(diagnostics[1].File, "main.lox")
+	is.Equal(err.Error(), ""+
+		"main.lox:2:5: error[E0001]: Unexpected character: '@' (U+0040).\n"+
+		"main.lox:1:10: error[E0010]: Expect expression.")
+
+	// nothing is reported until the error is rendered
+	is.Equal(stderr.String(), "")
+	engine.Render(err)
+	is.True(strings.Contains(stderr.String(), "error[E0010]: Expect expression.\n --> main.lox:1:10\n"))
+
+	// errors from an earlier Compile don't carry over
+	_, err = engine.Compile("print 1;")
+	is.NoErr(err)
+}
+
+func TestEngine_RuntimeError(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout, stderr bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout), WithStderr(&stderr))
+
+	program, err := engine.Compile(`print 1; print 1 / 0; print 2;`)
+	is.NoErr(err)
+
+	err = engine.Run(context.Background(), program)
+
+	var runtimeErr RuntimeError
+	is.True(errors.As(err, &runtimeErr))
+	is.Equal(runtimeErr.Code, ErrDivideByZero)
+
+	var diagnostics Diagnostics
+	is.True(errors.As(err, &diagnostics))
+	is.Equal(len(diagnostics), 1)
+	is.Equal(diagnostics[0].Code, ErrDivideByZero)
+	is.Equal(diagnostics[0].Line, 1)
+	is.Equal(diagnostics[0].Column, 18)
+	is.Equal(diagnostics[0].EndColumn, 19)
+
+	is.Equal(stdout.String(), "1\n")
+	is.Equal(stderr.String(), "")
+
+	engine.Render(err)
+	is.True(strings.Contains(stderr.String(), "error[E0022]"))
+}
+
+func TestEngine_RunCancelled(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout))
+
+	program, err := engine.Compile(`print 1;`)
+	is.NoErr(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = engine.Run(ctx, program)
+	is.True(errors.Is(err, context.Canceled))
+	is.Equal(stdout.String(), "")
+
+	var runtimeErr RuntimeError
+	is.True(errors.As(err, &runtimeErr))
+	is.Equal(runtimeErr.Code, ErrCancelled)
+}
+
+func TestEngine_RunStream(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout, stderr bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout), WithStderr(&stderr))
+
+	err := engine.RunStream(context.Background(), strings.NewReader("print 1;\nprint 2 +;\nprint 3;"))
+	is.True(errors.Is(err, ParseError))
+
+	// statements before the syntax error have already run
+	is.Equal(stdout.String(), "1\n")
+}
+
+func TestEngine_RunStreamAssert(t *testing.T) {
+	is := is2.New(t)
+
+	engine := NewEngine(WithStdout(&bytes.Buffer{}), WithStderr(&bytes.Buffer{}))
+
+	err := engine.RunStream(context.Background(), strings.NewReader("var a = 1;\nprint a;\nassert a>2 and\n  a < 0;"))
+
+	// the source being streamed is still there to quote
+	var runtimeErr RuntimeError
+	is.True(errors.As(err, &runtimeErr))
+	is.Equal(runtimeErr.Error(), "assert failed: a>2 and\n  a < 0")
+}
+
+func TestEngine_RunTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"while loop", `while (true) {}`},
+		{"for loop", `for (;;) {}`},
+		{"tail recursion", `fun spin(n) { return spin(n + 1); } spin(0);`},
+		{"recursion", `fun fib(n) { if (n < 2) return n; return fib(n - 1) + fib(n - 2); } fib(100);`},
+		{"negated call", `fun spin() { while (true) {} } print -spin();`},
+		{"not of a call", `fun spin() { while (true) {} } print !spin();`},
+		{"unary in a return", `fun spin() { while (true) {} } fun f() { return -spin(); } print f();`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			var stdout bytes.Buffer
+			engine := NewEngine(WithStdout(&stdout), WithStderr(&bytes.Buffer{}))
+			program, err := engine.Compile(tt.source)
+			is.NoErr(err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			err = engine.Run(ctx, program)
+			is.True(errors.Is(err, context.DeadlineExceeded))
+			// nothing carries on after the deadline
+			is.Equal(stdout.String(), "")
+
+			var runtimeErr RuntimeError
+			is.True(errors.As(err, &runtimeErr))
+			is.Equal(runtimeErr.Code, ErrCancelled)
+		})
+	}
+}
+
+func TestEngine_RunCancelledWhileRunning(t *testing.T) {
+	is := is2.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stdout bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout), WithStderr(&bytes.Buffer{}))
+	engine.Define("cancel", func(args Args) (Value, error) {
+		cancel()
+		return nil, nil
+	})
+
+	program, err := engine.Compile(`
+var i = 0;
+while (i < 10) {
+	print i;
+	if (i == 2) cancel();
+	i = i + 1;
+}
+`)
+	is.NoErr(err)
+
+	err = engine.Run(ctx, program)
+	is.True(errors.Is(err, context.Canceled))
+
+	// the loop stops before its next iteration
+	is.Equal(stdout.String(), "0\n1\n2\n")
+
+	var runtimeErr RuntimeError
+	is.True(errors.As(err, &runtimeErr))
+	is.Equal(runtimeErr.Token.lexeme, "while")
+}
+
+func TestEngine_RunCancelledBeforeStatementWithoutToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		wantLine   int
+		wantColumn int
+	}{
+		{"first statement", `print "after";`, 1, 1},
+		{"later statement", "var a = 1;\nstop();\nprint \"after\";", 2, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var stdout, stderr bytes.Buffer
+			engine := NewEngine(WithStdout(&stdout), WithStderr(&stderr))
+			engine.Define("stop", func(args Args) (Value, error) {
+				cancel()
+				return nil, nil
+			})
+			if !strings.Contains(tt.source, "stop") {
+				cancel()
+			}
+
+			program, err := engine.Compile(tt.source)
+			is.NoErr(err)
+
+			err = engine.Run(ctx, program)
+			is.True(errors.Is(err, context.Canceled))
+			is.Equal(stdout.String(), "")
+
+			var diagnostics Diagnostics
+			is.True(errors.As(err, &diagnostics))
+			is.Equal(diagnostics[0].Line, tt.wantLine)
+			is.Equal(diagnostics[0].Column, tt.wantColumn)
+
+			engine.Render(err)
+			is.True(strings.Contains(stderr.String(), "error[E0030]"))
+		})
+	}
+}
+
+func TestEngine_ConcurrentOutput(t *testing.T) {
+	is := is2.New(t)
+
+	const engines = 8
+
+	outputs := make([]bytes.Buffer, engines)
+	errs := make([]error, engines)
+
+	var wg sync.WaitGroup
+	for n := 0; n < engines; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+
+			engine := NewEngine(WithStdout(&outputs[n]), WithGlobals(map[string]any{"id": n}))
+			program, err := engine.Compile(`for (var i = 0; i < 100; i = i + 1) print id;`)
+			if err != nil {
+				errs[n] = err
+				return
+			}
+			errs[n] = engine.Run(context.Background(), program)
+		}(n)
+	}
+	wg.Wait()
+
+	// each engine's output only has its own lines in it
+	for n := 0; n < engines; n++ {
+		is.NoErr(errs[n])
+		is.Equal(outputs[n].String(), strings.Repeat(fmt.Sprintf("%d\n", n), 100))
+	}
+}
+
+func TestWithMaxCallDepth(t *testing.T) {
+	tests := []struct {
+		depth     int
+		wantPanic bool
+	}{
+		{-1, true},
+		{0, true},
+		{1, false},
+		{MaxCallDepthLimit, false},
+		{MaxCallDepthLimit + 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.depth), func(t *testing.T) {
+			is := is2.New(t)
+
+			defer func() {
+				is.Equal(recover() != nil, tt.wantPanic)
+			}()
+
+			NewEngine(WithMaxCallDepth(tt.depth))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package lox
+
+import (
+	"fmt"
+)
+
+type Environment struct {
+	EnclosingEnv *Environment
+	Values       map[string]any
+}
+
+func NewGlobalEnvironment() *Environment {
+	env := Environment{
+		EnclosingEnv: nil,
+		Values:       map[string]any{},
+	}
+
+	env.Define("clock", NewNativeFunction("clock", 0, clock))
+
+	return &env
+}
+
+func NewEnvironment() *Environment {
+	return &Environment{
+		Values: map[string]any{},
+	}
+}
+
+func NewEnvironmentWithEnclosing(env *Environment) *Environment {
+	return &Environment{
+		EnclosingEnv: env,
+		Values:       map[string]any{},
+	}
+}
+
+func (e *Environment) Define(key string, value any) {
+	e.Values[key] = value
+}
+
+func (e *Environment) Get(name Token) (any, error) {
+	for env := e; env != nil; env = env.EnclosingEnv {
+		val, ok := env.Values[name.lexeme]
+		if ok {
+			return val, nil
+		}
+	}
+
+	return nil, e.undefinedVariable(name)
+}
+
+func (e *Environment) Assign(name Token, value any) error {
+	for env := e; env != nil; env = env.EnclosingEnv {
+		_, ok := env.Values[name.lexeme]
+		if ok {
+			env.Values[name.lexeme] = value
+			return nil
+		}
+	}
+
+	return e.undefinedVariable(name)
+}
+
+// undefinedVariable creates the error for a name which isn't defined anywhere in the environment chain,
+// suggesting the closest name that is, or a keyword, if there's one near enough.
+func (e *Environment) undefinedVariable(name Token) RuntimeError {
+	err := NewRuntimeError_UndefinedVariable(name)
+
+	candidates := e.Names()
+	for keyword := range keywords {
+		candidates = append(candidates, keyword)
+	}
+
+	if suggestion, ok := suggestName(name.lexeme, candidates); ok {
+		err.Help = fmt.Sprintf("did you mean '%s'?", suggestion)
+	}
+
+	return err
+}
+
+// Names lists every name visible from this environment, including those in enclosing environments.
+func (e *Environment) Names() []string {
+	var names []string
+	seen := map[string]bool{}
+
+	for env := e; env != nil; env = env.EnclosingEnv {
+		for name := range env.Values {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
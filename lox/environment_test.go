@@ -0,0 +1,96 @@
+package lox
+
+import (
+	is2 "github.com/matryer/is"
+	"testing"
+)
+
+func TestEnvironment_DefineAndGet(t *testing.T) {
+	is := is2.New(t)
+	env := Environment{
+		Values: map[string]any{},
+	}
+
+	env.Define("foo", 123)
+
+	result, err := env.Get(Token{
+		tokenType: IDENTIFIER,
+		lexeme:    "foo",
+		literal:   nil,
+		line:      0,
+	})
+
+	is.NoErr(err)
+	is.Equal(result, 123)
+}
+
+func TestEnvironment_GetRuntimeError(t *testing.T) {
+	is := is2.New(t)
+	env := Environment{
+		Values: map[string]any{},
+	}
+
+	token := Token{
+		tokenType: IDENTIFIER,
+		lexeme:    "foo",
+		literal:   nil,
+		line:      0,
+	}
+
+	result, err := env.Get(Token{
+		tokenType: IDENTIFIER,
+		lexeme:    "foo",
+		literal:   nil,
+		line:      0,
+	})
+
+	is.Equal(err, RuntimeError{
+		Token: token,
+		Code:  ErrUndefinedVariable,
+		Help:  "did you mean 'for'?",
+		msg:   "Undefined variable 'foo'.",
+	})
+
+	is.Equal(result, nil)
+}
+
+func TestEnvironment_UndefinedVariableSuggestions(t *testing.T) {
+	tests := []struct {
+		description string
+		name        string
+		help        string
+	}{
+		{description: "suggests a transposed name", name: "cuont", help: "did you mean 'count'?"},
+		{description: "suggests names from enclosing environments", name: "totl", help: "did you mean 'total'?"},
+		{description: "suggests natives", name: "clok", help: "did you mean 'clock'?"},
+		{description: "suggests keywords", name: "retrun", help: "did you mean 'return'?"},
+		{description: "doesn't suggest distant names", name: "banana", help: ""},
+	}
+
+	globals := NewGlobalEnvironment()
+	globals.Define("total", 0)
+	env := NewEnvironmentWithEnclosing(globals)
+	env.Define("count", 1)
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			is := is2.New(t)
+			name := Token{tokenType: IDENTIFIER, lexeme: tc.name}
+
+			_, err := env.Get(name)
+			is.Equal(err.(RuntimeError).Help, tc.help)
+
+			err = env.Assign(name, 2)
+			is.Equal(err.(RuntimeError).Help, tc.help)
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	is := is2.New(t)
+
+	is.Equal(editDistance("", "abc"), 3)
+	is.Equal(editDistance("kitten", "sitting"), 3)
+	is.Equal(editDistance("cuont", "count"), 1)
+	is.Equal(editDistance("naïve", "naive"), 1)
+}
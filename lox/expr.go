@@ -1,6 +1,6 @@
 // this file is auto-generated with bin/generate_ast
 // DO NOT EDIT
-package main
+package lox
 
 type Expr interface {
 	Accept(visitor ExprVisitor) (any, error)
@@ -68,15 +68,35 @@ func (t Logical) Accept(visitor ExprVisitor) (any, error) {
 }
 
 type Call struct {
-	callee    Expr
-	paren     Token
-	arguments []Expr
+	callee         Expr
+	paren          Token
+	arguments      []Expr
+	namedArguments []NamedArgument
 }
 
 func (t Call) Accept(visitor ExprVisitor) (any, error) {
 	return visitor.VisitCall(t)
 }
 
+type Get struct {
+	object Expr
+	name   Token
+}
+
+func (t Get) Accept(visitor ExprVisitor) (any, error) {
+	return visitor.VisitGet(t)
+}
+
+type Set struct {
+	object Expr
+	name   Token
+	value  Expr
+}
+
+func (t Set) Accept(visitor ExprVisitor) (any, error) {
+	return visitor.VisitSet(t)
+}
+
 type ExprVisitor interface {
 	VisitUnary(expr Unary) (any, error)
 	VisitBinary(expr Binary) (any, error)
@@ -86,4 +106,6 @@ type ExprVisitor interface {
 	VisitAssign(expr Assign) (any, error)
 	VisitLogical(expr Logical) (any, error)
 	VisitCall(expr Call) (any, error)
+	VisitGet(expr Get) (any, error)
+	VisitSet(expr Set) (any, error)
 }
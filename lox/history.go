@@ -0,0 +1,99 @@
+package lox
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory is how many lines of REPL history are kept.
+const maxHistory = 1000
+
+// DefaultHistoryFile is where the REPL saves the lines typed into it, ~/.golox_history,
+// or "" if the home directory can't be found.
+func DefaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".golox_history")
+}
+
+// history is the lines typed into the REPL, oldest first. They're appended to a file as they're added,
+// so they're kept between sessions.
+type history struct {
+	// path is the file the history is saved to, or "" if it isn't saved
+	path    string
+	entries []string
+}
+
+// loadHistory reads the history saved at path, which doesn't need to exist yet.
+func loadHistory(path string) (*history, error) {
+	h := &history{path: path}
+	if path == "" {
+		return h, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loadHistory error, os.Open: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.entries = append(h.entries, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("loadHistory error, reading %s: %w", path, err)
+	}
+
+	// the file only grows while it's being appended to, so it's cut back down when it's loaded
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+		if err := os.WriteFile(path, []byte(strings.Join(h.entries, "\n")+"\n"), 0600); err != nil {
+			return nil, fmt.Errorf("loadHistory error, os.WriteFile: %w", err)
+		}
+	}
+
+	return h, nil
+}
+
+// add saves a line typed into the REPL. Blank lines, and repeats of the line before, aren't saved.
+func (h *history) add(line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == line {
+		return nil
+	}
+
+	h.entries = append(h.entries, line)
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[1:]
+	}
+
+	if h.path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("history.add error, os.OpenFile: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		return fmt.Errorf("history.add error, writing %s: %w", h.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package lox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestHistory(t *testing.T) {
+	is := is2.New(t)
+
+	path := filepath.Join(t.TempDir(), ".golox_history")
+
+	h, err := loadHistory(path)
+	is.NoErr(err)
+	is.Equal(len(h.entries), 0)
+
+	is.NoErr(h.add("var a = 1;"))
+	is.NoErr(h.add("print a;"))
+
+	// a new session picks up where the last one left off
+	h, err = loadHistory(path)
+	is.NoErr(err)
+	is.Equal(h.entries, []string{"var a = 1;", "print a;"})
+}
+
+func TestLoadHistory_Truncates(t *testing.T) {
+	is := is2.New(t)
+
+	path := filepath.Join(t.TempDir(), ".golox_history")
+
+	var lines []string
+	for n := 0; n < maxHistory+10; n++ {
+		lines = append(lines, fmt.Sprintf("print %d;", n))
+	}
+	is.NoErr(os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600))
+
+	h, err := loadHistory(path)
+	is.NoErr(err)
+	is.Equal(len(h.entries), maxHistory)
+	is.Equal(h.entries[0], "print 10;")
+
+	saved, err := os.ReadFile(path)
+	is.NoErr(err)
+	is.Equal(strings.Count(string(saved), "\n"), maxHistory)
+}
@@ -0,0 +1,820 @@
+package lox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxCallDepth is how deeply Lox calls can nest unless configured otherwise.
+const DefaultMaxCallDepth = 10000
+
+// MaxCallDepthLimit is the deepest MaxCallDepth can be. Each Lox call takes around 10KB of Go stack,
+// so this keeps well inside Go's default 1GB limit, which crashes the whole process when it's exceeded.
+const MaxCallDepthLimit = 50000
+
+type Interpreter struct {
+	Lox         *Lox
+	Environment *Environment
+
+	// MaxCallDepth is how deeply Lox calls can nest before a stack overflow RuntimeError is raised.
+	// Calls in tail position don't count towards it. It's capped at MaxCallDepthLimit.
+	MaxCallDepth int
+
+	// callStack is shared between the copies of the Interpreter made while walking the tree
+	callStack *callStack
+
+	// ctx stops the program when it's cancelled. It's checked on every loop iteration and function call.
+	ctx context.Context
+
+	// budget is nil unless the program has Limits, see WithLimits
+	budget *budget
+}
+
+func NewInterpreter(lox *Lox, environment *Environment) Interpreter {
+	return Interpreter{
+		Lox:          lox,
+		Environment:  environment,
+		MaxCallDepth: DefaultMaxCallDepth,
+		callStack:    &callStack{},
+		ctx:          context.Background(),
+	}
+}
+
+func (i Interpreter) maxCallDepth() int {
+	if i.MaxCallDepth > MaxCallDepthLimit {
+		return MaxCallDepthLimit
+	}
+
+	return i.MaxCallDepth
+}
+
+// WithContext returns a copy of the Interpreter which stops running the program when ctx is cancelled.
+func (i Interpreter) WithContext(ctx context.Context) Interpreter {
+	i.ctx = ctx
+	return i
+}
+
+// checkCancelled returns a RuntimeError at token if the Interpreter's context has been cancelled.
+func (i Interpreter) checkCancelled(token Token) error {
+	if err := i.ctx.Err(); err != nil {
+		return NewRuntimeError_Cancelled(token, err)
+	}
+
+	return nil
+}
+
+func (i Interpreter) InterpretStatements(statements []Stmt) error {
+	for _, stmt := range statements {
+		err := i.execute(stmt)
+		if err != nil {
+			var runtimeErr RuntimeError
+			if errors.As(err, &runtimeErr) {
+				i.Lox.runtimeError()
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i Interpreter) VisitStmtExpression(stmt StmtExpression) (any, error) {
+	return i.evaluate(stmt.expression)
+}
+
+func (i Interpreter) VisitStmtWhile(stmt StmtWhile) (any, error) {
+	for {
+		if err := i.checkCancelled(stmt.keyword); err != nil {
+			return nil, err
+		}
+
+		cond, err := i.evaluate(stmt.condition)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isTruthy(cond) {
+			break
+		}
+
+		err = i.execute(stmt.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (i Interpreter) VisitStmtPrint(stmt StmtPrint) (any, error) {
+	value, err := i.evaluate(stmt.expression)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = fmt.Fprintln(i.Lox.stdout(), stringify(value))
+	return nil, nil
+}
+
+func (i Interpreter) VisitStmtVar(stmt StmtVar) (any, error) {
+	if err := i.allocate(stmt.name, 1); err != nil {
+		return nil, err
+	}
+
+	if stmt.initializer == nil {
+		i.Environment.Define(stmt.name.lexeme, nil)
+		return nil, nil
+	}
+
+	value, err := i.evaluate(stmt.initializer)
+	if err != nil {
+		return nil, err
+	}
+
+	i.Environment.Define(stmt.name.lexeme, value)
+	return value, nil
+}
+
+func (i Interpreter) VisitStmtBlock(expr StmtBlock) (any, error) {
+	err := i.executeBlock(expr.statements, *NewEnvironmentWithEnclosing(i.Environment))
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (i Interpreter) VisitStmtIf(expr StmtIf) (any, error) {
+	cond, err := i.evaluate(expr.condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if isTruthy(cond) {
+		return nil, i.execute(expr.thenBranch)
+	} else if expr.elseBranch != nil {
+		return nil, i.execute(expr.elseBranch)
+	}
+
+	return nil, nil
+}
+
+func (i Interpreter) VisitStmtFunction(stmt StmtFunction) (any, error) {
+	if err := i.allocate(stmt.name, 1); err != nil {
+		return nil, err
+	}
+
+	f := LoxFunction{
+		declaration: stmt,
+		closure:     i.Environment,
+	}
+	i.Environment.Define(stmt.name.lexeme, f)
+	return nil, nil
+}
+
+func (i Interpreter) VisitStmtEnum(stmt StmtEnum) (any, error) {
+	if err := i.allocate(stmt.name, 1+len(stmt.members)); err != nil {
+		return nil, err
+	}
+
+	i.Environment.Define(stmt.name.lexeme, NewLoxEnum(stmt))
+	return nil, nil
+}
+
+func (i Interpreter) VisitStmtRecord(stmt StmtRecord) (any, error) {
+	if err := i.allocate(stmt.name, 1); err != nil {
+		return nil, err
+	}
+
+	i.Environment.Define(stmt.name.lexeme, NewLoxRecord(stmt))
+	return nil, nil
+}
+
+// VisitStmtReturn unwinds to the enclosing LoxFunction.call by returning a Return as the error.
+// A call in tail position is not made here: the callee and arguments are handed back
+// so LoxFunction.call can run it in its own loop without growing the Go stack.
+func (i Interpreter) VisitStmtReturn(stmt StmtReturn) (any, error) {
+	if stmt.value == nil {
+		return nil, Return{}
+	}
+
+	if call, ok := stmt.value.(Call); ok {
+		callee, arguments, namedArguments, err := i.evaluateCall(call)
+		if err != nil {
+			return nil, err
+		}
+
+		if fn, ok := callee.(LoxFunction); ok && len(namedArguments) == 0 && len(arguments) == fn.arity() {
+			return nil, Return{
+				tailCall: &TailCall{
+					function:  fn,
+					arguments: arguments,
+					paren:     call.paren,
+				},
+			}
+		}
+
+		value, err := i.invoke(call, callee, arguments, namedArguments)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, Return{value: value}
+	}
+
+	value, err := i.evaluate(stmt.value)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, Return{value: value}
+}
+
+func (i Interpreter) VisitStmtAssert(stmt StmtAssert) (any, error) {
+	var passed bool
+	details := ""
+
+	// for comparisons, evaluate each side separately so the failure message can show both operands
+	if binary, ok := stmt.condition.(Binary); ok && isComparisonOperator(binary.operator.tokenType) {
+		left, err := i.evaluate(binary.left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := i.evaluate(binary.right)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := binaryOperation(binary.operator, left, right)
+		if err != nil {
+			return nil, err
+		}
+
+		passed = isTruthy(result)
+		details = fmt.Sprintf(" (left: %s, right: %s)", assertOperand(left), assertOperand(right))
+	} else {
+		cond, err := i.evaluate(stmt.condition)
+		if err != nil {
+			return nil, err
+		}
+
+		passed = isTruthy(cond)
+	}
+
+	if passed {
+		return nil, nil
+	}
+
+	msg := fmt.Sprintf("assert failed: %s%s", stmt.source, details)
+	if stmt.message != nil {
+		message, err := i.evaluate(stmt.message)
+		if err != nil {
+			return nil, err
+		}
+
+		msg = fmt.Sprintf("%s: %s", msg, stringify(message))
+	}
+
+	return nil, NewRuntimeErrorWithCode(ErrAssertionFailed, stmt.keyword, msg)
+}
+
+func (i Interpreter) executeBlock(statements []Stmt, environment Environment) error {
+	prevEnv := i.Environment
+
+	for _, stmt := range statements {
+		i.Environment = &environment
+		err := i.execute(stmt)
+		if err != nil {
+			i.Environment = prevEnv
+			return err
+		}
+	}
+
+	i.Environment = prevEnv
+	return nil
+}
+
+func (i Interpreter) InterpretExpression(expr Expr) (any, error) {
+	val, err := i.evaluate(expr)
+	if err != nil {
+		i.Lox.runtimeError()
+		return nil, err
+	}
+
+	_, _ = fmt.Fprintln(i.Lox.stdout(), stringify(val))
+	return val, nil
+}
+
+func (i Interpreter) VisitLiteral(expr Literal) (any, error) {
+	return expr.value, nil
+}
+
+func (i Interpreter) VisitGrouping(expr Grouping) (any, error) {
+	return i.evaluate(expr.expression)
+}
+
+func (i Interpreter) VisitUnary(expr Unary) (any, error) {
+	right, err := i.evaluate(expr.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.operator.tokenType {
+	case MINUS:
+		err := checkNumberOperand(expr.operator, right)
+		if err != nil {
+			return nil, err
+		}
+		return -1.0 * right.(float64), nil // nb will panic if not a float
+	case BANG:
+		return !isTruthy(right), nil
+	}
+
+	return nil, nil
+}
+
+func (i Interpreter) VisitCall(expr Call) (any, error) {
+	callee, arguments, namedArguments, err := i.evaluateCall(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.invoke(expr, callee, arguments, namedArguments)
+}
+
+// evaluateCall evaluates the callee and arguments of a call expression, without making the call.
+func (i Interpreter) evaluateCall(expr Call) (any, []Object, []NamedValue, error) {
+	callee, err := i.evaluate(expr.callee)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var arguments []Object
+	for _, arg := range expr.arguments {
+		argEval, err := i.evaluate(arg)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		arguments = append(arguments, argEval)
+	}
+
+	var namedArguments []NamedValue
+	for _, arg := range expr.namedArguments {
+		argEval, err := i.evaluate(arg.value)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		namedArguments = append(namedArguments, NamedValue{
+			name:  arg.name,
+			value: argEval,
+		})
+	}
+
+	return callee, arguments, namedArguments, nil
+}
+
+// invoke calls an already evaluated callee.
+func (i Interpreter) invoke(expr Call, callee any, arguments []Object, namedArguments []NamedValue) (any, error) {
+	fn, ok := callee.(LoxCallable)
+	if !ok {
+		return nil, NewRuntimeErrorWithCode(ErrNotCallable, expr.paren, "Can only call functions and classes.")
+	}
+
+	if _, ok := fn.(LoxNamedCallable); !ok && len(namedArguments) > 0 {
+		return nil, NewRuntimeErrorWithCode(ErrArity, expr.paren, fmt.Sprintf("%s does not accept named arguments.", stringify(fn)))
+	}
+
+	// natives with a negative arity check their own arguments
+	if len(namedArguments) == 0 && fn.arity() >= 0 && len(arguments) != fn.arity() {
+		return nil, NewRuntimeErrorWithCode(ErrArity, expr.paren, fmt.Sprintf("Expected %d arguments but got %d arguments instead.", fn.arity(), len(arguments)))
+	}
+
+	if err := i.checkCancelled(expr.paren); err != nil {
+		err := err.(RuntimeError)
+		err.Traceback = i.callStack.snapshot()
+		return nil, err
+	}
+
+	// guard against runaway recursion taking down the Go runtime with it
+	if maxDepth := i.maxCallDepth(); i.callStack.depth() >= maxDepth {
+		err := NewRuntimeError_StackOverflow(expr.paren, maxDepth)
+		err.Traceback = i.callStack.snapshot()
+		return nil, err
+	}
+
+	i.callStack.push(CallFrame{
+		Function: callableName(fn),
+		CallSite: expr.paren,
+	})
+	defer i.callStack.pop()
+
+	var result any
+	var err error
+	if native, ok := fn.(*NativeFunction); ok {
+		result, err = native.callAt(i, expr.paren, arguments)
+	} else if len(namedArguments) > 0 {
+		result, err = fn.(LoxNamedCallable).callNamed(i, expr.paren, arguments, namedArguments)
+	} else {
+		result, err = fn.call(i, arguments)
+	}
+
+	// the innermost call an error passes through records the stack as it was when the error happened
+	switch e := err.(type) {
+	case RuntimeError:
+		if e.Traceback == nil {
+			e.Traceback = i.callStack.snapshot()
+			return nil, e
+		}
+	case BudgetExceededError:
+		if e.Traceback == nil {
+			e.Traceback = i.callStack.snapshot()
+			return nil, e
+		}
+	}
+
+	return result, err
+}
+
+type LoxCallable interface {
+	call(i Interpreter, arguments []Object) (Object, error)
+	arity() int
+}
+
+// NamedValue is an evaluated `name: value` call argument.
+type NamedValue struct {
+	name  Token
+	value Object
+}
+
+// LoxNamedCallable is implemented by callables which also accept named arguments.
+// They are responsible for checking their own arity when named arguments are given.
+type LoxNamedCallable interface {
+	LoxCallable
+	callNamed(i Interpreter, paren Token, arguments []Object, namedArguments []NamedValue) (Object, error)
+}
+
+// LoxInstance is implemented by values which have properties that can be accessed with '.'.
+type LoxInstance interface {
+	get(name Token) (any, error)
+}
+
+func (i Interpreter) VisitGet(expr Get) (any, error) {
+	object, err := i.evaluate(expr.object)
+	if err != nil {
+		return nil, err
+	}
+
+	if instance, ok := object.(LoxInstance); ok {
+		return instance.get(expr.name)
+	}
+
+	return nil, NewRuntimeErrorWithCode(ErrUndefinedProperty, expr.name, "Only instances have properties.")
+}
+
+// LoxMutableInstance is implemented by values whose properties can be assigned to with '.'.
+type LoxMutableInstance interface {
+	set(name Token, value any) error
+}
+
+func (i Interpreter) VisitSet(expr Set) (any, error) {
+	object, err := i.evaluate(expr.object)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, ok := object.(LoxMutableInstance)
+	if !ok {
+		return nil, NewRuntimeErrorWithCode(ErrUndefinedProperty, expr.name, "Only instances have fields.")
+	}
+
+	value, err := i.evaluate(expr.value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = instance.set(expr.name, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (i Interpreter) VisitBinary(expr Binary) (any, error) {
+	left, err := i.evaluate(expr.left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := i.evaluate(expr.right)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.operator.tokenType == PLUS {
+		if err := i.checkStringLength(expr.operator, left, right); err != nil {
+			return nil, err
+		}
+	}
+
+	return binaryOperation(expr.operator, left, right)
+}
+
+// binaryOperation applies a binary operator to operands which have already been evaluated.
+func binaryOperation(operator Token, left any, right any) (any, error) {
+	// equality is defined for operands of any type
+	switch operator.tokenType {
+	case BANG_EQUAL:
+		return !isEqual(left, right), nil
+	case EQUAL_EQUAL:
+		return isEqual(left, right), nil
+	}
+
+	operandsAreBothStrings := checkStringOperands(left, right)
+
+	// support for string concatenation
+	if operandsAreBothStrings {
+		leftStr, _ := left.(string)
+		rightStr, _ := right.(string)
+
+		switch operator.tokenType {
+		case PLUS:
+			return fmt.Sprintf("%s%s", leftStr, rightStr), nil
+		}
+
+		return nil, NewRuntimeErrorWithCode(ErrOperandType, operator, fmt.Sprintf("Cannot use operator '%s' with string operands", operator.lexeme))
+	}
+
+	err := checkNumberOperands(operator, left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operator.tokenType {
+	case MINUS:
+		return left.(float64) - right.(float64), nil
+	case PLUS:
+		return left.(float64) + right.(float64), nil
+	case SLASH:
+		err = checkDivideByZero(operator, right)
+		if err != nil {
+			return nil, err
+		}
+
+		return left.(float64) / right.(float64), nil
+	case STAR:
+		return left.(float64) * right.(float64), nil
+	case GREATER:
+		return left.(float64) > right.(float64), nil
+	case GREATER_EQUAL:
+		return left.(float64) >= right.(float64), nil
+	case LESS:
+		return left.(float64) < right.(float64), nil
+	case LESS_EQUAL:
+		return left.(float64) <= right.(float64), nil
+	}
+
+	return nil, nil
+}
+
+func (i Interpreter) VisitLogical(expr Logical) (any, error) {
+	if expr.operator.tokenType == OR {
+		lv, err := i.evaluate(expr.left)
+		if err != nil {
+			return nil, err
+		}
+
+		if isTruthy(lv) {
+			return lv, nil
+		}
+
+		rv, err := i.evaluate(expr.right)
+		if err != nil {
+			return nil, err
+		}
+
+		return rv, nil
+	}
+
+	if expr.operator.tokenType == AND {
+		lv, err := i.evaluate(expr.left)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isTruthy(lv) {
+			return lv, nil
+		}
+
+		rv, err := i.evaluate(expr.right)
+		if err != nil {
+			return nil, err
+		}
+
+		return rv, nil
+	}
+
+	return nil, NewRuntimeError(expr.operator, "Logical operator must be 'or' or 'and'.")
+}
+
+func (i Interpreter) VisitVar(expr Var) (any, error) {
+	return i.Environment.Get(expr.name)
+}
+
+func (i Interpreter) VisitAssign(expr Assign) (any, error) {
+	val, err := i.evaluate(expr.value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.Environment.Assign(expr.name, val)
+	if err != nil {
+		return nil, err
+	}
+
+	return val, nil
+}
+
+func (i Interpreter) execute(stmt Stmt) error {
+	if err := i.step(stmt); err != nil {
+		return err
+	}
+
+	_, err := stmt.Accept(i)
+	return err
+}
+
+func (i Interpreter) evaluate(expr Expr) (any, error) {
+	if err := i.step(expr); err != nil {
+		return nil, err
+	}
+
+	return expr.Accept(i)
+}
+
+type loxStringer interface {
+	toString() string
+}
+
+// assertOperand shows an operand of a failed assert. Strings are quoted, so that "1" can be told apart from 1.
+func assertOperand(val any) string {
+	if s, ok := val.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return stringify(val)
+}
+
+func stringify(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "nil"
+	case loxStringer:
+		return v.toString()
+	}
+
+	return fmt.Sprintf("%v", val)
+}
+
+func isComparisonOperator(t TokenType) bool {
+	switch t {
+	case EQUAL_EQUAL, BANG_EQUAL, GREATER, GREATER_EQUAL, LESS, LESS_EQUAL:
+		return true
+	}
+
+	return false
+}
+
+func checkNumberOperand(op Token, expr any) error {
+	_, ok := expr.(float64)
+	if !ok {
+		return NewRuntimeErrorWithCode(ErrOperandType, op, "Operand must be a number.")
+	}
+	return nil
+}
+
+func checkNumberOperands(op Token, left any, right any) error {
+	_, leftOk := left.(float64)
+	_, rightOk := right.(float64)
+	if !leftOk || !rightOk {
+		return NewRuntimeErrorWithCode(ErrOperandType, op, "Operands must both be numbers.")
+	}
+	return nil
+}
+
+func checkStringOperands(left any, right any) bool {
+	_, leftIsString := left.(string)
+	_, rightIsString := right.(string)
+	return leftIsString && rightIsString
+}
+
+func checkDivideByZero(op Token, right any) error {
+	if right == 0.0 {
+		return NewRuntimeError_DivideByZero(op)
+	}
+
+	return nil
+}
+
+// isTruthy checks if x is a truthy value or not.
+// Truthy being defined as not falsey.
+// Falsey being defined as:
+//   - false
+//   - nil
+func isTruthy(x any) bool {
+	if x == nil {
+		return false
+	}
+
+	xBool, ok := x.(bool)
+
+	// if x is not a boolean, (and not nil) return true
+	if !ok {
+		return true
+	}
+
+	return xBool
+}
+
+func isEqual(a any, b any) bool {
+	if a == nil && b == nil {
+		return true
+	}
+	if a == nil {
+		return false
+	}
+
+	// enum values are singletons, so two values are equal only if they are the same member
+	if av, ok := a.(*LoxEnumValue); ok {
+		bv, ok := b.(*LoxEnumValue)
+		return ok && av == bv
+	}
+
+	// records are compared structurally
+	if ar, ok := a.(*LoxRecordInstance); ok {
+		br, ok := b.(*LoxRecordInstance)
+		return ok && ar.equals(br)
+	}
+
+	if ao, ok := a.(*GoObject); ok {
+		bo, ok := b.(*GoObject)
+		return ok && ao.equals(bo)
+	}
+
+	return a == b
+}
+
+type RuntimeError struct {
+	Token Token
+	Code  ErrorCode
+	// Help is an optional hint on how to fix the error
+	Help string
+	// Traceback is the stack of calls the error happened in, innermost last. See StackTrace.
+	Traceback []CallFrame
+	// Err is the Go error which caused this one, if it came from a native function
+	Err error
+	msg string
+}
+
+func NewRuntimeError(token Token, msg string) RuntimeError {
+	return NewRuntimeErrorWithCode(ErrRuntime, token, msg)
+}
+
+func NewRuntimeErrorWithCode(code ErrorCode, token Token, msg string) RuntimeError {
+	return RuntimeError{
+		Token: token,
+		Code:  code,
+		msg:   msg,
+	}
+}
+
+func NewRuntimeError_DivideByZero(token Token) RuntimeError {
+	return NewRuntimeErrorWithCode(ErrDivideByZero, token, "Cannot divide by zero")
+}
+
+func NewRuntimeError_StackOverflow(token Token, maxCallDepth int) RuntimeError {
+	err := NewRuntimeErrorWithCode(ErrStackOverflow, token, fmt.Sprintf("Stack overflow: maximum call depth %d exceeded", maxCallDepth))
+	err.Help = "check for recursion without a base case, or raise the limit with -max-call-depth"
+	return err
+}
+
+// NewRuntimeError_Cancelled is the error a program stops with when its context is cancelled.
+// It wraps ctx.Err(), so errors.Is(err, context.DeadlineExceeded) tells a timeout apart from other errors.
+func NewRuntimeError_Cancelled(token Token, ctxErr error) RuntimeError {
+	err := NewRuntimeErrorWithCode(ErrCancelled, token, fmt.Sprintf("Execution stopped: %s", ctxErr))
+	err.Err = ctxErr
+	return err
+}
+
+func NewRuntimeError_UndefinedVariable(name Token) RuntimeError {
+	return NewRuntimeErrorWithCode(ErrUndefinedVariable, name, fmt.Sprintf("Undefined variable '%s'.", name.lexeme))
+}
+
+func (e RuntimeError) Error() string {
+	return e.msg
+}
+
+func (e RuntimeError) Unwrap() error {
+	return e.Err
+}
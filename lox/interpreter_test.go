@@ -0,0 +1,436 @@
+package lox
+
+import (
+	"github.com/matryer/is"
+	"testing"
+)
+
+func TestInterpreter_Interpret(t *testing.T) {
+	type test struct {
+		description string
+		input       Expr
+		expected    any
+	}
+
+	tests := []test{
+		{
+			description: "it adds two numbers",
+			input: Binary{
+				operator: Token{
+					tokenType: PLUS,
+					lexeme:    "+",
+					literal:   nil,
+					line:      0,
+				},
+				left:  Literal{value: 1.0},
+				right: Literal{value: 2.0},
+			},
+			expected: 3.0,
+		},
+		{
+			description: "it subtracts two numbers",
+			input: Binary{
+				operator: Token{
+					tokenType: MINUS,
+					lexeme:    "-",
+					literal:   nil,
+					line:      0,
+				},
+				left:  Literal{value: 1.0},
+				right: Literal{value: 2.0},
+			},
+			expected: -1.0,
+		},
+		{
+			description: "concatenates two strings",
+			input: Binary{
+				operator: Token{
+					tokenType: PLUS,
+					lexeme:    "+",
+					literal:   nil,
+					line:      0,
+				},
+				left:  Literal{value: "hello "},
+				right: Literal{value: "world!"},
+			},
+			expected: "hello world!",
+		},
+	}
+
+	is := is.NewRelaxed(t)
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			interpreter := Interpreter{
+				Lox: &Lox{},
+			}
+
+			result, err := interpreter.InterpretExpression(tc.input)
+
+			is.NoErr(err)
+			is.Equal(result, tc.expected)
+		})
+	}
+}
+
+func TestInterpreter_Interpret_DivideByZero(t *testing.T) {
+	is := is.New(t)
+	input := Binary{
+		operator: Token{
+			tokenType: SLASH,
+			lexeme:    "/",
+			literal:   nil,
+			line:      0,
+		},
+		left:  Literal{value: 10.0},
+		right: Literal{value: 0.0},
+	}
+
+	interpreter := Interpreter{
+		Lox: &Lox{},
+	}
+
+	_, err := interpreter.InterpretExpression(input)
+
+	is.True(err != nil)
+	is.True(interpreter.Lox.hadRuntimeError)
+	is.Equal(err, RuntimeError{
+		Token: input.operator,
+		Code:  ErrDivideByZero,
+		msg:   "Cannot divide by zero",
+	})
+}
+
+// interpretSource scans, parses and executes source in a fresh global environment.
+func interpretSource(t *testing.T, source string) (*Environment, error) {
+	t.Helper()
+	return interpretSourceWithLox(t, &Lox{}, source)
+}
+
+func interpretSourceWithLox(t *testing.T, lox *Lox, source string) (*Environment, error) {
+	t.Helper()
+	is := is.New(t)
+
+	scanner := Scanner{
+		lox:    lox,
+		source: source,
+		line:   1,
+	}
+	tokens, err := scanner.scanTokens()
+	is.NoErr(err)
+
+	parser := Parser{
+		Lox:    lox,
+		Tokens: tokens,
+		Source: source,
+	}
+	statements, err := parser.Parse()
+	is.NoErr(err)
+
+	env := NewGlobalEnvironment()
+	interpreter := NewInterpreter(lox, env)
+	if lox.MaxCallDepth > 0 {
+		interpreter.MaxCallDepth = lox.MaxCallDepth
+	}
+
+	return env, interpreter.InterpretStatements(statements)
+}
+
+func TestInterpreter_Assert(t *testing.T) {
+	tests := []struct {
+		description string
+		source      string
+		expected    string
+	}{
+		{
+			description: "it passes on a truthy condition",
+			source:      "var x = 3; assert x == 3;",
+			expected:    "",
+		},
+		{
+			description: "it reports both operands of a failing comparison",
+			source:      "var x = 2; assert x == 3;",
+			expected:    "assert failed: x == 3 (left: 2, right: 3)",
+		},
+		{
+			description: "it appends the message",
+			source:      `var name = "bob"; assert name != "bob", "name is reserved";`,
+			expected:    `assert failed: name != "bob" (left: "bob", right: "bob"): name is reserved`,
+		},
+		{
+			description: "it reports the source of non-comparison conditions",
+			source:      "var ok = true; assert !ok and (1 + 2) * -3;",
+			expected:    "assert failed: !ok and (1 + 2) * -3",
+		},
+		{
+			description: "it quotes string operands",
+			source:      `assert "1" == 1;`,
+			expected:    `assert failed: "1" == 1 (left: "1", right: 1)`,
+		},
+		{
+			description: "it reports the source of a falsey condition",
+			source:      "fun check(a, b) {} assert check(1, -2);",
+			expected:    "assert failed: check(1, -2)",
+		},
+		{
+			description: "it reports the source as it was written",
+			source:      "var a = 1; assert a>2;",
+			expected:    "assert failed: a>2 (left: 1, right: 2)",
+		},
+		{
+			description: "it reports named arguments as they were written",
+			source:      "record P(x); var p = P(1); var q = P(2); assert p.with(x: 5) == q;",
+			expected:    "assert failed: p.with(x: 5) == q (left: P(x: 5), right: P(x: 2))",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			is := is.New(t)
+
+			_, err := interpretSource(t, tc.source)
+			if tc.expected == "" {
+				is.NoErr(err)
+				return
+			}
+
+			runtimeErr, ok := err.(RuntimeError)
+			is.True(ok)
+			is.Equal(runtimeErr.Error(), tc.expected)
+			is.Equal(runtimeErr.Token.tokenType, ASSERT)
+		})
+	}
+}
+
+func TestInterpreter_Enum(t *testing.T) {
+	is := is.New(t)
+
+	env, err := interpretSource(t, `
+		enum Color { Red, Green, Blue, }
+		var green = Color.Green;
+		var ordinal = green.ordinal;
+		var name = Color.Blue.name;
+		var same = green == Color.Green;
+		var different = Color.Red == Color.Blue;
+	`)
+	is.NoErr(err)
+
+	get := func(name string) any {
+		v, err := env.Get(Token{tokenType: IDENTIFIER, lexeme: name})
+		is.NoErr(err)
+		return v
+	}
+
+	is.Equal(get("ordinal"), 1.0)
+	is.Equal(get("name"), "Blue")
+	is.Equal(get("same"), true)
+	is.Equal(get("different"), false)
+	is.Equal(stringify(get("green")), "Color.Green")
+	is.Equal(stringify(get("Color")), "<enum Color>")
+}
+
+func TestInterpreter_EnumUndefinedMember(t *testing.T) {
+	is := is.New(t)
+
+	_, err := interpretSource(t, "enum Color { Red } var c = Color.Purple;")
+
+	is.Equal(err.Error(), "Enum Color has no member 'Purple'.")
+}
+
+func TestInterpreter_EnumsWithSameMemberNamesAreNotEqual(t *testing.T) {
+	is := is.New(t)
+
+	env, err := interpretSource(t, `
+		enum Light { On, Off }
+		enum Switch { On, Off }
+		var equal = Light.On == Switch.On;
+	`)
+	is.NoErr(err)
+
+	equal, _ := env.Get(Token{tokenType: IDENTIFIER, lexeme: "equal"})
+	is.Equal(equal, false)
+}
+
+func TestInterpreter_Record(t *testing.T) {
+	is := is.New(t)
+
+	env, err := interpretSource(t, `
+		record Point(x, y);
+		var p = Point(1, 2);
+		var q = Point(y: 2, x: 1);
+		var moved = p.with(x: 5);
+		var x = p.x;
+		var movedX = moved.x;
+		var movedY = moved.y;
+		var equal = p == q;
+		var notEqual = p == moved;
+	`)
+	is.NoErr(err)
+
+	get := func(name string) any {
+		v, err := env.Get(Token{tokenType: IDENTIFIER, lexeme: name})
+		is.NoErr(err)
+		return v
+	}
+
+	is.Equal(get("x"), 1.0)
+	is.Equal(get("movedX"), 5.0)
+	is.Equal(get("movedY"), 2.0)
+	is.Equal(get("equal"), true)
+	is.Equal(get("notEqual"), false)
+	is.Equal(stringify(get("moved")), "Point(x: 5, y: 2)")
+	is.Equal(stringify(get("Point")), "<record Point>")
+}
+
+func TestInterpreter_RecordErrors(t *testing.T) {
+	tests := []struct {
+		description string
+		source      string
+		expected    string
+	}{
+		{
+			description: "fields are read-only",
+			source:      "record Point(x, y); var p = Point(1, 2); p.x = 3;",
+			expected:    "Cannot assign to field 'x' of Point, records are immutable.",
+		},
+		{
+			description: "with rejects unknown fields",
+			source:      "record Point(x, y); var p = Point(1, 2); p.with(z: 3);",
+			expected:    "Record Point has no field 'z'.",
+		},
+		{
+			description: "constructor checks arity",
+			source:      "record Point(x, y); Point(1);",
+			expected:    "Expected 2 arguments but got 1 arguments instead.",
+		},
+		{
+			description: "constructor requires every field",
+			source:      "record Point(x, y); Point(x: 1);",
+			expected:    "Missing value for field 'y' of Point.",
+		},
+		{
+			description: "records of different types are not equal",
+			source:      "record A(v); record B(v); assert A(1) == B(1);",
+			expected:    "assert failed: A(1) == B(1) (left: A(v: 1), right: B(v: 1))",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			is := is.New(t)
+
+			_, err := interpretSource(t, tc.source)
+
+			is.True(err != nil)
+			is.Equal(err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestInterpreter_FunctionReturnsValue(t *testing.T) {
+	is := is.New(t)
+
+	env, err := interpretSource(t, `
+		var base = 10;
+		fun add(a, b) { return base + a + b; }
+		fun fib(n) {
+			if (n < 2) return n;
+			return fib(n - 1) + fib(n - 2);
+		}
+		var sum = add(1, 2);
+		var f = fib(15);
+	`)
+	is.NoErr(err)
+
+	sum, _ := env.Get(Token{tokenType: IDENTIFIER, lexeme: "sum"})
+	is.Equal(sum, 13.0)
+	f, _ := env.Get(Token{tokenType: IDENTIFIER, lexeme: "f"})
+	is.Equal(f, 610.0)
+}
+
+func TestInterpreter_TailCallSelfRecursion(t *testing.T) {
+	is := is.New(t)
+
+	env, err := interpretSource(t, `
+		fun count(n, acc) {
+			if (n == 0) return acc;
+			return count(n - 1, acc + 1);
+		}
+		var result = count(1000000, 0);
+	`)
+	is.NoErr(err)
+
+	result, _ := env.Get(Token{tokenType: IDENTIFIER, lexeme: "result"})
+	is.Equal(result, 1000000.0)
+}
+
+func TestInterpreter_TailCallMutualRecursion(t *testing.T) {
+	is := is.New(t)
+
+	env, err := interpretSource(t, `
+		fun isEven(n) {
+			if (n == 0) return true;
+			return isOdd(n - 1);
+		}
+		fun isOdd(n) {
+			if (n == 0) return false;
+			return isEven(n - 1);
+		}
+		var even = isEven(1000000);
+		var odd = isOdd(1000000);
+	`)
+	is.NoErr(err)
+
+	even, _ := env.Get(Token{tokenType: IDENTIFIER, lexeme: "even"})
+	is.Equal(even, true)
+	odd, _ := env.Get(Token{tokenType: IDENTIFIER, lexeme: "odd"})
+	is.Equal(odd, false)
+}
+
+func TestInterpreter_StackOverflow(t *testing.T) {
+	is := is.New(t)
+
+	_, err := interpretSource(t, `
+		fun runaway(n) {
+			return 1 + runaway(n + 1);
+		}
+		runaway(0);
+	`)
+
+	runtimeErr, ok := err.(RuntimeError)
+	is.True(ok)
+	is.Equal(runtimeErr.Error(), "Stack overflow: maximum call depth 10000 exceeded")
+	is.Equal(runtimeErr.Token.tokenType, RIGHT_PAREN)
+}
+
+func TestInterpreter_MaxCallDepthIsConfigurable(t *testing.T) {
+	is := is.New(t)
+	source := `
+		fun depth(n) {
+			if (n == 0) return 0;
+			return 1 + depth(n - 1);
+		}
+		var result = depth(40);
+	`
+
+	env, err := interpretSourceWithLox(t, &Lox{MaxCallDepth: 50}, source)
+	is.NoErr(err)
+	result, _ := env.Get(Token{tokenType: IDENTIFIER, lexeme: "result"})
+	is.Equal(result, 40.0)
+
+	_, err = interpretSourceWithLox(t, &Lox{MaxCallDepth: 30}, source)
+	is.Equal(err.Error(), "Stack overflow: maximum call depth 30 exceeded")
+}
+
+func TestInterpreter_MaxCallDepthIsCapped(t *testing.T) {
+	is := is.New(t)
+
+	// deeper than this would take more Go stack than the runtime allows, and crash
+	_, err := interpretSourceWithLox(t, &Lox{MaxCallDepth: 1 << 30}, `
+		fun runaway(n) {
+			return 1 + runaway(n + 1);
+		}
+		runaway(0);
+	`)
+	is.Equal(err.Error(), "Stack overflow: maximum call depth 50000 exceeded")
+}
@@ -0,0 +1,387 @@
+package lox
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// errInterrupted is returned by readLine when Ctrl-C is pressed, to throw away what has been typed.
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads the lines typed into the REPL, showing prompt before each one. It returns io.EOF
+// when there's no more input.
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+// plainReader reads lines from input which isn't a terminal, or can't be put into raw mode.
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r plainReader) readLine(prompt string) (string, error) {
+	_, _ = fmt.Fprint(r.out, prompt)
+
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+
+	return r.scanner.Text(), nil
+}
+
+// lineEditor reads lines from a terminal in raw mode, so they can be edited as they're typed:
+//
+//   - Left and Right, Home and End, or Ctrl-B, Ctrl-F, Ctrl-A and Ctrl-E move the cursor
+//   - Backspace and Delete remove a character, Ctrl-W the word before the cursor,
+//     and Ctrl-U and Ctrl-K everything before or after it
+//   - Up and Down, or Ctrl-P and Ctrl-N, go through earlier lines
+//   - Ctrl-R searches back through earlier lines for what's typed next
+//   - Ctrl-C throws the line away, and Ctrl-D on an empty line ends the input
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history *history
+	// makeRaw puts the terminal into raw mode while a line is read, returning a function which restores it
+	makeRaw func() (func() error, error)
+}
+
+// editState is a line being edited.
+type editState struct {
+	prompt string
+	line   []rune
+	// cursor is the index in line of the rune the cursor is on
+	cursor int
+
+	// historyIdx is the history entry being shown, or len(entries) for the new line,
+	// which is kept in draft while looking through history
+	historyIdx int
+	draft      []rune
+}
+
+func ctrl(key rune) rune {
+	return key & 0x1f
+}
+
+const (
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+)
+
+func (ed *lineEditor) readLine(prompt string) (string, error) {
+	if ed.makeRaw != nil {
+		restore, err := ed.makeRaw()
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = restore() }()
+	}
+
+	s := &editState{
+		prompt:     prompt,
+		historyIdx: len(ed.history.entries),
+	}
+	ed.refresh(s)
+
+	for {
+		key, _, err := ed.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case '\r', '\n':
+			return ed.submit(s)
+		case ctrl('C'):
+			ed.write("^C\r\n")
+			return "", errInterrupted
+		case ctrl('D'):
+			if len(s.line) == 0 {
+				return "", io.EOF
+			}
+			s.delete()
+		case ctrl('R'):
+			submitted, err := ed.search(s)
+			if err != nil {
+				return "", err
+			}
+			if submitted {
+				return ed.submit(s)
+			}
+		case keyEscape:
+			if err := ed.escape(s); err != nil {
+				return "", err
+			}
+		default:
+			ed.edit(s, key)
+		}
+
+		ed.refresh(s)
+	}
+}
+
+// edit handles a key which doesn't need more input to be read.
+func (ed *lineEditor) edit(s *editState, key rune) {
+	switch key {
+	case ctrl('A'):
+		s.cursor = 0
+	case ctrl('E'):
+		s.cursor = len(s.line)
+	case ctrl('B'):
+		s.left()
+	case ctrl('F'):
+		s.right()
+	case ctrl('P'):
+		s.previous(ed.history)
+	case ctrl('N'):
+		s.next(ed.history)
+	case ctrl('H'), keyBackspace:
+		s.backspace()
+	case ctrl('W'):
+		s.deleteWord()
+	case ctrl('U'):
+		s.line = s.line[s.cursor:]
+		s.cursor = 0
+	case ctrl('K'):
+		s.line = s.line[:s.cursor]
+	case ctrl('L'):
+		ed.write("\x1b[H\x1b[2J")
+	case '\t':
+		s.insert(' ', ' ')
+	default:
+		if unicode.IsPrint(key) {
+			s.insert(key)
+		}
+	}
+}
+
+// escape handles the escape sequences sent by the arrow keys, Home, End and Delete.
+func (ed *lineEditor) escape(s *editState) error {
+	next, _, err := ed.in.ReadRune()
+	if err != nil {
+		return err
+	}
+	if next != '[' && next != 'O' {
+		return nil
+	}
+
+	code, _, err := ed.in.ReadRune()
+	if err != nil {
+		return err
+	}
+
+	// sequences like ESC [ 3 ~ have a number before the ~
+	if code >= '0' && code <= '9' {
+		final, _, err := ed.in.ReadRune()
+		if err != nil {
+			return err
+		}
+		if final != '~' {
+			return nil
+		}
+
+		switch code {
+		case '1', '7':
+			s.cursor = 0
+		case '4', '8':
+			s.cursor = len(s.line)
+		case '3':
+			s.delete()
+		}
+		return nil
+	}
+
+	switch code {
+	case 'A':
+		s.previous(ed.history)
+	case 'B':
+		s.next(ed.history)
+	case 'C':
+		s.right()
+	case 'D':
+		s.left()
+	case 'H':
+		s.cursor = 0
+	case 'F':
+		s.cursor = len(s.line)
+	}
+
+	return nil
+}
+
+// search is reverse incremental search through the history, started by Ctrl-R. Typing adds to
+// what's searched for, and Ctrl-R again finds the next older match. Enter runs the match, Ctrl-G or
+// Ctrl-C goes back to the line as it was, and any other key leaves the match in the line to be edited.
+func (ed *lineEditor) search(s *editState) (submitted bool, err error) {
+	original := append([]rune(nil), s.line...)
+	var query []rune
+	matchIdx := len(ed.history.entries)
+	found := true
+
+	find := func(from int) {
+		for idx := from; idx >= 0; idx-- {
+			if idx < len(ed.history.entries) && strings.Contains(ed.history.entries[idx], string(query)) {
+				matchIdx = idx
+				found = true
+				s.setLine([]rune(ed.history.entries[idx]))
+				return
+			}
+		}
+		found = false
+	}
+
+	for {
+		status := "reverse-i-search"
+		if !found {
+			status = "failed reverse-i-search"
+		}
+		ed.write(fmt.Sprintf("\r(%s)`%s': %s\x1b[K", status, string(query), string(s.line)))
+
+		key, _, err := ed.in.ReadRune()
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case key == '\r' || key == '\n':
+			return true, nil
+		case key == ctrl('G') || key == ctrl('C'):
+			s.setLine(original)
+			return false, nil
+		case key == ctrl('R'):
+			find(matchIdx - 1)
+		case key == ctrl('H') || key == keyBackspace:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				find(len(ed.history.entries) - 1)
+			}
+		case unicode.IsPrint(key):
+			query = append(query, key)
+			find(matchIdx)
+		default:
+			// leave search with the match, then handle the key as usual
+			_ = ed.in.UnreadRune()
+			return false, nil
+		}
+	}
+}
+
+func (ed *lineEditor) submit(s *editState) (string, error) {
+	s.cursor = len(s.line)
+	ed.refresh(s)
+	ed.write("\r\n")
+
+	line := string(s.line)
+	// not being able to save history isn't worth ending the session over
+	_ = ed.history.add(line)
+
+	return line, nil
+}
+
+// refresh redraws the prompt and line, and puts the cursor back where it was.
+func (ed *lineEditor) refresh(s *editState) {
+	var buf bytes.Buffer
+	buf.WriteString("\r")
+	buf.WriteString(s.prompt)
+	buf.WriteString(string(s.line))
+	buf.WriteString("\x1b[K")
+	if back := len(s.line) - s.cursor; back > 0 {
+		buf.WriteString(fmt.Sprintf("\x1b[%dD", back))
+	}
+
+	ed.write(buf.String())
+}
+
+func (ed *lineEditor) write(s string) {
+	_, _ = io.WriteString(ed.out, s)
+}
+
+func (s *editState) insert(runes ...rune) {
+	line := make([]rune, 0, len(s.line)+len(runes))
+	line = append(line, s.line[:s.cursor]...)
+	line = append(line, runes...)
+	s.line = append(line, s.line[s.cursor:]...)
+	s.cursor += len(runes)
+}
+
+func (s *editState) backspace() {
+	if s.cursor == 0 {
+		return
+	}
+
+	s.line = append(s.line[:s.cursor-1], s.line[s.cursor:]...)
+	s.cursor--
+}
+
+func (s *editState) delete() {
+	if s.cursor == len(s.line) {
+		return
+	}
+
+	s.line = append(s.line[:s.cursor], s.line[s.cursor+1:]...)
+}
+
+// deleteWord deletes back from the cursor to the start of the word before it.
+func (s *editState) deleteWord() {
+	start := s.cursor
+	for start > 0 && unicode.IsSpace(s.line[start-1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(s.line[start-1]) {
+		start--
+	}
+
+	s.line = append(s.line[:start], s.line[s.cursor:]...)
+	s.cursor = start
+}
+
+func (s *editState) left() {
+	if s.cursor > 0 {
+		s.cursor--
+	}
+}
+
+func (s *editState) right() {
+	if s.cursor < len(s.line) {
+		s.cursor++
+	}
+}
+
+func (s *editState) previous(h *history) {
+	if s.historyIdx == 0 {
+		return
+	}
+	if s.historyIdx == len(h.entries) {
+		s.draft = s.line
+	}
+
+	s.historyIdx--
+	s.setLine([]rune(h.entries[s.historyIdx]))
+}
+
+func (s *editState) next(h *history) {
+	if s.historyIdx >= len(h.entries) {
+		return
+	}
+
+	s.historyIdx++
+	if s.historyIdx == len(h.entries) {
+		s.setLine(s.draft)
+	} else {
+		s.setLine([]rune(h.entries[s.historyIdx]))
+	}
+}
+
+// setLine replaces the line, with the cursor at the end.
+func (s *editState) setLine(line []rune) {
+	s.line = append([]rune(nil), line...)
+	s.cursor = len(s.line)
+}
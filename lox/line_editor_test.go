@@ -0,0 +1,111 @@
+package lox
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestLineEditor_ReadLine(t *testing.T) {
+	const (
+		up    = "\x1b[A"
+		down  = "\x1b[B"
+		right = "\x1b[C"
+		left  = "\x1b[D"
+		home  = "\x1b[H"
+		end   = "\x1b[F"
+		del   = "\x1b[3~"
+	)
+
+	tests := []struct {
+		name    string
+		history []string
+		keys    string
+		want    string
+	}{
+		{"typing", nil, "print 1;\r", "print 1;"},
+		{"backspace", nil, "print 12\x7f;\r", "print 1;"},
+		{"insert after moving left", nil, "print ;" + left + "1\r", "print 1;"},
+		{"home and end", nil, "rint 1" + home + "p" + end + ";\r", "print 1;"},
+		{"ctrl-a and ctrl-e", nil, "rint 1\x01p\x05;\r", "print 1;"},
+		{"delete", nil, "print 1;;" + left + del + "\r", "print 1;"},
+		{"moving right stops at the end", nil, "print 1" + right + right + ";\r", "print 1;"},
+		{"delete word", nil, "print hello world\x17\x171;\r", "print 1;"},
+		{"kill to start of line", nil, "oops\x15print 1;\r", "print 1;"},
+		{"kill to end of line", nil, "print 1;oops" + left + left + left + left + "\x0b\r", "print 1;"},
+		{"up goes back through history", []string{"a", "b"}, up + up + "\r", "a"},
+		{"down comes back to the new line", []string{"a", "b"}, "draft" + up + up + down + down + "\r", "draft"},
+		{"history stops at the oldest line", []string{"a"}, up + up + up + "\r", "a"},
+		{"reverse search", []string{"print 1;", "var x = 2;", "print 3;"}, "\x12print\r", "print 3;"},
+		{"reverse search again for older matches", []string{"print 1;", "var x = 2;", "print 3;"}, "\x12print\x12\r", "print 1;"},
+		{"reverse search then edit", []string{"var x = 2;"}, "\x12var" + end + "\x7f\x7f3;\r", "var x = 3;"},
+		{"cancelled reverse search", []string{"var x = 2;"}, "print\x12var\x07;\r", "print;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			var out strings.Builder
+			ed := &lineEditor{
+				in:      bufio.NewReader(strings.NewReader(tt.keys)),
+				out:     &out,
+				history: &history{entries: tt.history},
+			}
+
+			line, err := ed.readLine("> ")
+			is.NoErr(err)
+			is.Equal(line, tt.want)
+		})
+	}
+}
+
+func TestLineEditor_ReadLineAddsToHistory(t *testing.T) {
+	is := is2.New(t)
+
+	ed := &lineEditor{
+		in:      bufio.NewReader(strings.NewReader("print 1;\rprint 1;\r\r")),
+		out:     io.Discard,
+		history: &history{},
+	}
+
+	for n := 0; n < 3; n++ {
+		_, err := ed.readLine("> ")
+		is.NoErr(err)
+	}
+
+	// blank lines and repeats aren't added
+	is.Equal(ed.history.entries, []string{"print 1;"})
+}
+
+func TestLineEditor_ReadLineInterruptedAndEOF(t *testing.T) {
+	is := is2.New(t)
+
+	var out strings.Builder
+	ed := &lineEditor{
+		in:      bufio.NewReader(strings.NewReader("oops\x03\x04")),
+		out:     &out,
+		history: &history{},
+	}
+
+	_, err := ed.readLine("> ")
+	is.True(errors.Is(err, errInterrupted))
+	is.True(strings.HasSuffix(out.String(), "^C\r\n"))
+
+	_, err = ed.readLine("> ")
+	is.True(errors.Is(err, io.EOF))
+}
+
+func TestLineEditor_Refresh(t *testing.T) {
+	is := is2.New(t)
+
+	var out strings.Builder
+	ed := &lineEditor{out: &out}
+
+	ed.refresh(&editState{prompt: "> ", line: []rune("print 1;"), cursor: 6})
+	is.Equal(out.String(), "\r> print 1;\x1b[K\x1b[2D")
+}
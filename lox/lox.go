@@ -0,0 +1,102 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Lox holds what the scanner, parser and interpreter share while a program is compiled and run:
+// where output goes, and the errors found so far. Engine is the simpler way to run Lox code.
+type Lox struct {
+	// MaxCallDepth overrides DefaultMaxCallDepth when set
+	MaxCallDepth int
+
+	// Stdin is where the REPL reads input, Stdout is where print statements write,
+	// and Stderr is where the REPL reports errors. They default to os.Stdin, os.Stdout and os.Stderr.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// source is the program currently being run, used to show snippets in diagnostics
+	source string
+	// file is the name of the file source came from, if any
+	file string
+
+	// diagnostics are the errors reported since the last program was compiled
+	diagnostics []Diagnostic
+
+	hadError        bool
+	hadRuntimeError bool
+}
+
+func (l *Lox) stdin() io.Reader {
+	if l.Stdin == nil {
+		return os.Stdin
+	}
+
+	return l.Stdin
+}
+
+func (l *Lox) stdout() io.Writer {
+	if l.Stdout == nil {
+		return os.Stdout
+	}
+
+	return l.Stdout
+}
+
+func (l *Lox) stderr() io.Writer {
+	if l.Stderr == nil {
+		return os.Stderr
+	}
+
+	return l.Stderr
+}
+
+// report records a problem found while compiling the program.
+func (l *Lox) report(d Diagnostic) Diagnostic {
+	d.File = l.file
+	if d.Err == nil {
+		d.Err = ParseError
+	}
+
+	l.diagnostics = append(l.diagnostics, d)
+	l.hadError = true
+	return d
+}
+
+func (l *Lox) error(code ErrorCode, token Token, message string) Diagnostic {
+	d := NewDiagnostic(code, token, message)
+	if token.tokenType == EOF {
+		d.Message = fmt.Sprintf("%s (at end of input)", message)
+	}
+
+	return l.report(d)
+}
+
+func (l *Lox) runtimeError() {
+	l.hadRuntimeError = true
+}
+
+// runtimeDiagnostics describes the error a program stopped with. Errors which aren't a RuntimeError,
+// such as ctx.Err(), are reported without a position.
+func (l *Lox) runtimeDiagnostics(err error) Diagnostics {
+	var runtimeErr RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		runtimeErr = NewRuntimeError(Token{}, err.Error())
+	}
+
+	d := NewDiagnostic(runtimeErr.Code, runtimeErr.Token, runtimeErr.Error())
+	d.File = l.file
+	d.Help = runtimeErr.Help
+	d.Err = err
+
+	return Diagnostics{d}
+}
+
+// render writes err to Stderr, with snippets of the source.
+func (l *Lox) render(err error) {
+	NewDiagnosticRenderer(l.stderr(), l.source).RenderError(err)
+}
@@ -0,0 +1,61 @@
+package lox
+
+import "fmt"
+
+// LoxEnum is the namespace created by an enum declaration.
+// Its members are accessed as properties, e.g. Color.Red.
+type LoxEnum struct {
+	name    string
+	members []*LoxEnumValue
+}
+
+// LoxEnumValue is a single member of an enum.
+// Enum values are only ever created by their declaration, so they compare by identity.
+type LoxEnumValue struct {
+	enum    *LoxEnum
+	name    string
+	ordinal int
+}
+
+func NewLoxEnum(stmt StmtEnum) *LoxEnum {
+	enum := &LoxEnum{name: stmt.name.lexeme}
+
+	for ordinal, member := range stmt.members {
+		enum.members = append(enum.members, &LoxEnumValue{
+			enum:    enum,
+			name:    member.lexeme,
+			ordinal: ordinal,
+		})
+	}
+
+	return enum
+}
+
+func (e *LoxEnum) get(name Token) (any, error) {
+	for _, member := range e.members {
+		if member.name == name.lexeme {
+			return member, nil
+		}
+	}
+
+	return nil, NewRuntimeErrorWithCode(ErrUndefinedProperty, name, fmt.Sprintf("Enum %s has no member '%s'.", e.name, name.lexeme))
+}
+
+func (e *LoxEnum) toString() string {
+	return "<enum " + e.name + ">"
+}
+
+func (v *LoxEnumValue) get(name Token) (any, error) {
+	switch name.lexeme {
+	case "name":
+		return v.name, nil
+	case "ordinal":
+		return float64(v.ordinal), nil
+	}
+
+	return nil, NewRuntimeErrorWithCode(ErrUndefinedProperty, name, fmt.Sprintf("Undefined property '%s' on %s.", name.lexeme, v.toString()))
+}
+
+func (v *LoxEnumValue) toString() string {
+	return v.enum.name + "." + v.name
+}
@@ -0,0 +1,74 @@
+package lox
+
+type LoxFunction struct {
+	declaration StmtFunction
+	closure     *Environment
+}
+
+// call runs the function body. Tail calls to other Lox functions come back as a TailCall
+// on the Return, and are run by looping here rather than by recursing through VisitCall,
+// so self- and mutually-recursive functions run in constant Go stack.
+func (l LoxFunction) call(i Interpreter, arguments []Object) (Object, error) {
+	fn := l
+
+	for {
+		if err := i.allocate(fn.declaration.name, len(arguments)); err != nil {
+			return nil, err
+		}
+
+		environment := NewEnvironmentWithEnclosing(fn.closure)
+
+		for i := 0; i < len(fn.declaration.params); i++ {
+			environment.Define(fn.declaration.params[i].lexeme, arguments[i])
+		}
+
+		err := i.executeBlock(fn.declaration.body.statements, *environment)
+		if err == nil {
+			return nil, nil
+		}
+
+		ret, ok := err.(Return)
+		if !ok {
+			return nil, err
+		}
+
+		if ret.tailCall == nil {
+			return ret.value, nil
+		}
+
+		// tail calls don't go back through invoke, so check here that the program hasn't been cancelled
+		if err := i.checkCancelled(ret.tailCall.paren); err != nil {
+			return nil, err
+		}
+
+		fn = ret.tailCall.function
+		arguments = ret.tailCall.arguments
+		i.callStack.replaceTop(fn.declaration.name.lexeme)
+	}
+}
+
+func (l LoxFunction) arity() int {
+	return len(l.declaration.params)
+}
+
+func (l LoxFunction) toString() string {
+	return "<fn " + l.declaration.name.lexeme + ">"
+}
+
+// Return is used to unwind the Go stack from a return statement back to the function call.
+// It is passed up as an error, but is not a failure.
+type Return struct {
+	value    Object
+	tailCall *TailCall
+}
+
+// TailCall is a call made in tail position, which is still to be run by the caller.
+type TailCall struct {
+	function  LoxFunction
+	arguments []Object
+	paren     Token
+}
+
+func (r Return) Error() string {
+	return "return"
+}
@@ -0,0 +1,192 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// recordWithMethod is the name of the copy-with-update method available on every record instance.
+const recordWithMethod = "with"
+
+// LoxRecord is the constructor created by a record declaration.
+// Calling it with one value per field, either positionally or by name, creates a LoxRecordInstance.
+type LoxRecord struct {
+	name   string
+	fields []string
+}
+
+// LoxRecordInstance is an immutable value created by a LoxRecord.
+// Two instances are equal when they come from the same record and all their fields are equal.
+type LoxRecordInstance struct {
+	record *LoxRecord
+	values []Object
+}
+
+func NewLoxRecord(stmt StmtRecord) *LoxRecord {
+	record := &LoxRecord{name: stmt.name.lexeme}
+
+	for _, field := range stmt.fields {
+		record.fields = append(record.fields, field.lexeme)
+	}
+
+	return record
+}
+
+func (r *LoxRecord) call(i Interpreter, arguments []Object) (Object, error) {
+	if err := i.allocate(Token{}, len(arguments)); err != nil {
+		return nil, err
+	}
+
+	return &LoxRecordInstance{
+		record: r,
+		values: arguments,
+	}, nil
+}
+
+func (r *LoxRecord) callNamed(i Interpreter, paren Token, arguments []Object, namedArguments []NamedValue) (Object, error) {
+	if len(arguments) > len(r.fields) {
+		return nil, NewRuntimeErrorWithCode(ErrArity, paren, fmt.Sprintf("Expected %d arguments but got %d arguments instead.", len(r.fields), len(arguments)))
+	}
+
+	values := make([]Object, len(r.fields))
+	isSet := make([]bool, len(r.fields))
+
+	for idx, arg := range arguments {
+		values[idx] = arg
+		isSet[idx] = true
+	}
+
+	for _, arg := range namedArguments {
+		idx, err := r.fieldIndex(arg.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if isSet[idx] {
+			return nil, NewRuntimeErrorWithCode(ErrArity, arg.name, fmt.Sprintf("Field '%s' of %s was given more than once.", arg.name.lexeme, r.name))
+		}
+
+		values[idx] = arg.value
+		isSet[idx] = true
+	}
+
+	for idx, set := range isSet {
+		if !set {
+			return nil, NewRuntimeErrorWithCode(ErrArity, paren, fmt.Sprintf("Missing value for field '%s' of %s.", r.fields[idx], r.name))
+		}
+	}
+
+	return r.call(i, values)
+}
+
+func (r *LoxRecord) arity() int {
+	return len(r.fields)
+}
+
+func (r *LoxRecord) fieldIndex(name Token) (int, error) {
+	for idx, field := range r.fields {
+		if field == name.lexeme {
+			return idx, nil
+		}
+	}
+
+	return 0, NewRuntimeErrorWithCode(ErrUndefinedProperty, name, fmt.Sprintf("Record %s has no field '%s'.", r.name, name.lexeme))
+}
+
+func (r *LoxRecord) toString() string {
+	return "<record " + r.name + ">"
+}
+
+func (r *LoxRecordInstance) get(name Token) (any, error) {
+	if name.lexeme == recordWithMethod {
+		return recordWith{instance: r}, nil
+	}
+
+	idx, err := r.record.fieldIndex(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.values[idx], nil
+}
+
+func (r *LoxRecordInstance) set(name Token, value any) error {
+	return NewRuntimeErrorWithCode(ErrImmutable, name, fmt.Sprintf("Cannot assign to field '%s' of %s, records are immutable.", name.lexeme, r.record.name))
+}
+
+func (r *LoxRecordInstance) equals(other *LoxRecordInstance) bool {
+	if r.record != other.record {
+		return false
+	}
+
+	for idx := range r.values {
+		if !isEqual(r.values[idx], other.values[idx]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *LoxRecordInstance) toString() string {
+	var sb strings.Builder
+	sb.WriteString(r.record.name)
+	sb.WriteString("(")
+
+	for idx, field := range r.record.fields {
+		if idx > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(field)
+		sb.WriteString(": ")
+		sb.WriteString(stringify(r.values[idx]))
+	}
+
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// recordWith is the `with` method bound to a record instance.
+// It returns a copy of the instance with the named fields replaced.
+type recordWith struct {
+	instance *LoxRecordInstance
+}
+
+func (w recordWith) call(i Interpreter, arguments []Object) (Object, error) {
+	return w.callNamed(i, Token{}, arguments, nil)
+}
+
+func (w recordWith) callNamed(i Interpreter, paren Token, arguments []Object, namedArguments []NamedValue) (Object, error) {
+	if len(arguments) > 0 {
+		return nil, NewRuntimeErrorWithCode(ErrArity, paren, fmt.Sprintf("'%s' only takes named arguments.", recordWithMethod))
+	}
+
+	if err := i.allocate(paren, len(w.instance.values)); err != nil {
+		return nil, err
+	}
+
+	values := make([]Object, len(w.instance.values))
+	copy(values, w.instance.values)
+
+	for _, arg := range namedArguments {
+		idx, err := w.instance.record.fieldIndex(arg.name)
+		if err != nil {
+			return nil, err
+		}
+
+		values[idx] = arg.value
+	}
+
+	return &LoxRecordInstance{
+		record: w.instance.record,
+		values: values,
+	}, nil
+}
+
+func (w recordWith) arity() int {
+	return 0
+}
+
+func (w recordWith) toString() string {
+	return "<fn " + w.instance.record.name + "." + recordWithMethod + ">"
+}
@@ -0,0 +1,256 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Value is a Lox value as seen from Go: nil, bool, float64, string, or one of the interpreter's own
+// types, such as a function or record instance.
+type Value = Object
+
+// NativeFunc is a Go function which can be called from Lox. Its result is converted with ToValue.
+// A returned error which isn't a RuntimeError is reported as one, at the call site, with the error as its Err.
+type NativeFunc func(args Args) (Value, error)
+
+// Variadic is the arity of native functions which take any number of arguments, and check them themselves with Args.
+const Variadic = -1
+
+// NativeFunction is a Go function made callable from Lox, see Engine.Define.
+type NativeFunction struct {
+	name   string
+	params int
+	fn     NativeFunc
+}
+
+// NewNativeFunction creates a native function which takes params arguments, or any number if params is Variadic.
+func NewNativeFunction(name string, params int, fn NativeFunc) *NativeFunction {
+	return &NativeFunction{
+		name:   name,
+		params: params,
+		fn:     fn,
+	}
+}
+
+func (n *NativeFunction) call(i Interpreter, arguments []Object) (Object, error) {
+	return n.callAt(i, Token{}, arguments)
+}
+
+// callAt calls the function, reporting any error at paren, the closing paren of the call.
+// A string it returns counts against the string length budget like one built by concatenation.
+func (n *NativeFunction) callAt(i Interpreter, paren Token, arguments []Object) (Object, error) {
+	result, err := n.fn(Args{
+		function:    n.name,
+		paren:       paren,
+		values:      arguments,
+		interpreter: i,
+	})
+	if err == nil {
+		value := ToValue(result)
+		if err := i.checkStringLength(paren, value, ""); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+
+	var runtimeErr RuntimeError
+	if errors.As(err, &runtimeErr) {
+		return nil, err
+	}
+
+	runtimeErr = NewRuntimeError(paren, fmt.Sprintf("%s: %s", n.name, err))
+	runtimeErr.Err = err
+	return nil, runtimeErr
+}
+
+func (n *NativeFunction) arity() int {
+	return n.params
+}
+
+func (n *NativeFunction) toString() string {
+	return "<native fn " + n.name + ">"
+}
+
+// Args are the arguments a NativeFunction was called with. Its methods return RuntimeErrors
+// which point at the call, so natives can return them as they are:
+//
+//	name, err := args.String(0)
+//	if err != nil {
+//		return nil, err
+//	}
+type Args struct {
+	function string
+	paren    Token
+	values   []Value
+	// interpreter is the one making the call, for natives which use up its budget
+	interpreter Interpreter
+}
+
+func (a Args) Len() int {
+	return len(a.values)
+}
+
+// Value is the argument at idx, or nil if there are fewer arguments than that.
+func (a Args) Value(idx int) Value {
+	if idx < 0 || idx >= len(a.values) {
+		return nil
+	}
+
+	return a.values[idx]
+}
+
+// Expect checks that there are exactly n arguments.
+func (a Args) Expect(n int) error {
+	return a.ExpectBetween(n, n)
+}
+
+// ExpectBetween checks that there are at least min and at most max arguments.
+func (a Args) ExpectBetween(min int, max int) error {
+	if len(a.values) >= min && len(a.values) <= max {
+		return nil
+	}
+
+	expected := fmt.Sprintf("%d", min)
+	if max != min {
+		expected = fmt.Sprintf("%d to %d", min, max)
+	}
+
+	return NewRuntimeErrorWithCode(ErrArity, a.paren, fmt.Sprintf("%s expected %s arguments but got %d arguments instead.", a.function, expected, len(a.values)))
+}
+
+func (a Args) String(idx int) (string, error) {
+	if err := a.expectIndex(idx); err != nil {
+		return "", err
+	}
+
+	s, ok := a.values[idx].(string)
+	if !ok {
+		return "", a.typeError(idx, "string")
+	}
+
+	return s, nil
+}
+
+func (a Args) Number(idx int) (float64, error) {
+	if err := a.expectIndex(idx); err != nil {
+		return 0, err
+	}
+
+	n, ok := a.values[idx].(float64)
+	if !ok {
+		return 0, a.typeError(idx, "number")
+	}
+
+	return n, nil
+}
+
+// Int is the argument at idx as an int, which must be a number without a fractional part.
+func (a Args) Int(idx int) (int, error) {
+	n, err := a.Number(idx)
+	if err != nil {
+		return 0, err
+	}
+
+	// math.MaxInt isn't a float64, and rounds up to -math.MinInt when it's converted to one
+	if n != math.Trunc(n) || n >= -math.MinInt || n < math.MinInt {
+		return 0, NewRuntimeErrorWithCode(ErrArgumentType, a.paren, fmt.Sprintf("Argument %d of %s must be an integer, got %v.", idx+1, a.function, n))
+	}
+
+	return int(n), nil
+}
+
+func (a Args) Bool(idx int) (bool, error) {
+	if err := a.expectIndex(idx); err != nil {
+		return false, err
+	}
+
+	b, ok := a.values[idx].(bool)
+	if !ok {
+		return false, a.typeError(idx, "boolean")
+	}
+
+	return b, nil
+}
+
+func (a Args) expectIndex(idx int) error {
+	if idx < len(a.values) {
+		return nil
+	}
+
+	return NewRuntimeErrorWithCode(ErrArity, a.paren, fmt.Sprintf("%s expected at least %d arguments but got %d arguments instead.", a.function, idx+1, len(a.values)))
+}
+
+func (a Args) typeError(idx int, expected string) error {
+	return NewRuntimeErrorWithCode(ErrArgumentType, a.paren, fmt.Sprintf("Argument %d of %s must be a %s, got %s.", idx+1, a.function, expected, typeName(a.values[idx])))
+}
+
+// typeName is the name of the type of a Lox value, for error messages.
+func typeName(value Value) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *LoxRecord:
+		return "record"
+	case *LoxRecordInstance:
+		return "record instance"
+	case *LoxEnum:
+		return "enum"
+	case *LoxEnumValue:
+		return "enum value"
+	case *LoxModule:
+		return "module"
+	case *GoObject:
+		return v.typeName()
+	case LoxCallable:
+		return "function"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// Module is a group of native functions, which Lox code calls as module.name(...). See Engine.DefineModule.
+type Module map[string]NativeFunc
+
+// LoxModule is the value a Module is defined as in Lox.
+type LoxModule struct {
+	name    string
+	members map[string]*NativeFunction
+}
+
+func NewLoxModule(name string, module Module) *LoxModule {
+	m := &LoxModule{
+		name:    name,
+		members: map[string]*NativeFunction{},
+	}
+
+	for member, fn := range module {
+		m.members[member] = NewNativeFunction(name+"."+member, Variadic, fn)
+	}
+
+	return m
+}
+
+func (m *LoxModule) get(name Token) (any, error) {
+	if fn, ok := m.members[name.lexeme]; ok {
+		return fn, nil
+	}
+
+	return nil, NewRuntimeErrorWithCode(ErrUndefinedProperty, name, fmt.Sprintf("Module %s has no member '%s'.", m.name, name.lexeme))
+}
+
+func (m *LoxModule) toString() string {
+	return "<module " + m.name + ">"
+}
+
+// clock is the number of seconds since the Unix epoch.
+func clock(args Args) (Value, error) {
+	return float64(time.Now().UnixMilli()) / 1000, nil
+}
@@ -0,0 +1,195 @@
+package lox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func runEngine(t *testing.T, engine *Engine, source string) error {
+	t.Helper()
+
+	program, err := engine.Compile(source)
+	if err != nil {
+		return err
+	}
+
+	return engine.Run(context.Background(), program)
+}
+
+func TestEngine_Define(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout), WithStderr(&bytes.Buffer{}))
+
+	engine.Define("repeat", func(args Args) (Value, error) {
+		if err := args.Expect(2); err != nil {
+			return nil, err
+		}
+
+		s, err := args.String(0)
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := args.Int(1)
+		if err != nil {
+			return nil, err
+		}
+
+		return strings.Repeat(s, n), nil
+	})
+
+	err := runEngine(t, engine, `print repeat("ab", 3); print repeat;`)
+	is.NoErr(err)
+	is.Equal(stdout.String(), "ababab\n<native fn repeat>\n")
+}
+
+func TestEngine_DefineArgumentErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		code    ErrorCode
+		message string
+	}{
+		{`add(1);`, ErrArity, "add expected 2 arguments but got 1 arguments instead."},
+		{`add(1, 2, 3);`, ErrArity, "add expected 2 arguments but got 3 arguments instead."},
+		{`add(1, "2");`, ErrArgumentType, "Argument 2 of add must be a number, got string."},
+		{`add(nil, 2);`, ErrArgumentType, "Argument 1 of add must be a number, got nil."},
+		{`add(add, 2);`, ErrArgumentType, "Argument 1 of add must be a number, got function."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			is := is2.New(t)
+
+			engine := NewEngine(WithStderr(&bytes.Buffer{}))
+			engine.Define("add", func(args Args) (Value, error) {
+				if err := args.Expect(2); err != nil {
+					return nil, err
+				}
+
+				a, err := args.Number(0)
+				if err != nil {
+					return nil, err
+				}
+
+				b, err := args.Number(1)
+				if err != nil {
+					return nil, err
+				}
+
+				return a + b, nil
+			})
+
+			err := runEngine(t, engine, tt.source)
+
+			var runtimeErr RuntimeError
+			is.True(errors.As(err, &runtimeErr))
+			is.Equal(runtimeErr.Code, tt.code)
+			is.Equal(runtimeErr.Error(), tt.message)
+			is.Equal(runtimeErr.Token.tokenType, RIGHT_PAREN)
+		})
+	}
+}
+
+func TestEngine_DefineGoError(t *testing.T) {
+	is := is2.New(t)
+
+	errNotFound := errors.New("not found")
+
+	engine := NewEngine(WithStderr(&bytes.Buffer{}))
+	engine.Define("lookup", func(args Args) (Value, error) {
+		return nil, errNotFound
+	})
+
+	err := runEngine(t, engine, `fun f() { lookup(); } f();`)
+
+	var runtimeErr RuntimeError
+	is.True(errors.As(err, &runtimeErr))
+	is.Equal(runtimeErr.Error(), "lookup: not found")
+	is.True(errors.Is(err, errNotFound))
+
+	// the native function shows up in the traceback
+	is.Equal(len(runtimeErr.Traceback), 2)
+	is.Equal(runtimeErr.Traceback[1].Function, "lookup")
+}
+
+func TestEngine_DefineModule(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout, stderr bytes.Buffer
+	engine := NewEngine(WithStdout(&stdout), WithStderr(&stderr))
+
+	engine.DefineModule("strings", Module{
+		"upper": func(args Args) (Value, error) {
+			s, err := args.String(0)
+			if err != nil {
+				return nil, err
+			}
+			return strings.ToUpper(s), nil
+		},
+		"contains": func(args Args) (Value, error) {
+			s, err := args.String(0)
+			if err != nil {
+				return nil, err
+			}
+			substr, err := args.String(1)
+			if err != nil {
+				return nil, err
+			}
+			return strings.Contains(s, substr), nil
+		},
+	})
+
+	err := runEngine(t, engine, `print strings.upper("lox"); print strings.contains("golox", "lox"); print strings; print strings.upper;`)
+	is.NoErr(err)
+	is.Equal(stdout.String(), "LOX\ntrue\n<module strings>\n<native fn strings.upper>\n")
+
+	err = runEngine(t, engine, `strings.lower("LOX");`)
+	var runtimeErr RuntimeError
+	is.True(errors.As(err, &runtimeErr))
+	is.Equal(runtimeErr.Code, ErrUndefinedProperty)
+	is.Equal(runtimeErr.Error(), "Module strings has no member 'lower'.")
+}
+
+func TestArgs(t *testing.T) {
+	is := is2.New(t)
+
+	args := Args{function: "f", values: []Value{"s", 1.5, true, 2.0}}
+
+	is.Equal(args.Len(), 4)
+	is.Equal(args.Value(0), "s")
+	is.Equal(args.Value(4), nil)
+	is.NoErr(args.ExpectBetween(1, 4))
+	is.True(args.ExpectBetween(5, 6) != nil)
+
+	s, err := args.String(0)
+	is.NoErr(err)
+	is.Equal(s, "s")
+
+	n, err := args.Number(1)
+	is.NoErr(err)
+	is.Equal(n, 1.5)
+
+	b, err := args.Bool(2)
+	is.NoErr(err)
+	is.Equal(b, true)
+
+	i, err := args.Int(3)
+	is.NoErr(err)
+	is.Equal(i, 2)
+
+	_, err = args.Int(1)
+	is.Equal(err.Error(), "Argument 2 of f must be an integer, got 1.5.")
+
+	_, err = Args{function: "f", values: []Value{float64(1 << 63)}}.Int(0)
+	is.Equal(err.Error(), "Argument 1 of f must be an integer, got 9.223372036854776e+18.")
+
+	_, err = args.String(4)
+	is.Equal(err.Error(), "f expected at least 5 arguments but got 4 arguments instead.")
+}
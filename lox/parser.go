@@ -1,32 +1,141 @@
-package main
+package lox
 
 import (
 	"errors"
 	"fmt"
-	"reflect"
+	"io"
+	"strings"
 )
 
 type Parser struct {
-	Lox     *Lox
-	Tokens  []Token
+	Lox    *Lox
+	Tokens []Token
+	// Source is the code Tokens were scanned from, which failed assert statements quote
+	Source  string
 	current int
+
+	// tokenSource, when set, is where Tokens are read from as the parser needs them.
+	// Tokens then only holds the declaration currently being parsed.
+	tokenSource TokenSource
+	readErr     error
+
+	// functionDepth counts the function bodies enclosing the current token, so stray returns can be rejected.
+	functionDepth int
+
+	// diagnostics collects every syntax error found, as parsing carries on after each one
+	diagnostics []Diagnostic
+}
+
+// TokenSource hands out tokens one at a time, ending with EOF. Scanner implements it.
+type TokenSource interface {
+	Next() (Token, error)
+}
+
+// sourceKeeper is a TokenSource which keeps the source code of the tokens it has handed out, as Scanner does,
+// until it's told they won't be needed again.
+type sourceKeeper interface {
+	text(start int, end int) string
+	discardTextBefore(offset int)
 }
 
+// NewStreamingParser creates a Parser which pulls tokens from source on demand,
+// so that large programs can be parsed, and run, one declaration at a time.
+func NewStreamingParser(lox *Lox, source TokenSource) *Parser {
+	return &Parser{
+		Lox:         lox,
+		tokenSource: source,
+	}
+}
+
+// Parse parses the whole program. When there are syntax errors it keeps going to find as many as it can,
+// then returns all of them as Diagnostics rather than a partial program.
 func (p *Parser) Parse() ([]Stmt, error) {
 	var statements []Stmt
 
-	for !p.isAtEnd() {
-		decl, err := p.declaration()
+	for {
+		decl, err := p.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if p.readErr != nil {
+			return nil, p.readErr
+		}
 		if err != nil {
-			return nil, err
+			continue
 		}
 		statements = append(statements, decl)
 	}
 
+	if len(p.diagnostics) > 0 {
+		return nil, Diagnostics(p.diagnostics)
+	}
+
 	return statements, nil
 }
 
+// ParseExpression parses input which is a single expression, without a semicolon after it, as typed at the REPL.
+func (p *Parser) ParseExpression() (Expr, error) {
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.isAtEnd() {
+		return nil, p.error(p.peek(), "Expect end of expression.")
+	}
+
+	return expr, nil
+}
+
+// Next parses the next top-level declaration, returning io.EOF once there are none left.
+// After a syntax error it returns ParseError, and can be called again to carry on with the following declaration.
+func (p *Parser) Next() (Stmt, error) {
+	p.discardParsedTokens()
+
+	if p.isAtEnd() {
+		if p.readErr != nil {
+			return nil, p.readErr
+		}
+
+		return nil, io.EOF
+	}
+
+	decl, err := p.declaration()
+	if p.readErr != nil {
+		return nil, p.readErr
+	}
+
+	return decl, err
+}
+
+// discardParsedTokens drops the tokens of declarations which have already been parsed,
+// keeping only the previous one, so a streaming parser's memory use doesn't grow with the program.
+func (p *Parser) discardParsedTokens() {
+	if p.tokenSource == nil || p.current < 2 {
+		return
+	}
+
+	p.Tokens = append(p.Tokens[:0], p.Tokens[p.current-1:]...)
+	p.current = 1
+
+	if keeper, ok := p.tokenSource.(sourceKeeper); ok {
+		keeper.discardTextBefore(p.Tokens[0].start)
+	}
+}
+
+// declaration parses a declaration or statement. If it has a syntax error, the parser
+// is synchronized to the start of the next statement before the error is returned.
 func (p *Parser) declaration() (Stmt, error) {
+	stmt, err := p.declarationOrStatement()
+	if err != nil {
+		p.synchronize()
+		return nil, err
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) declarationOrStatement() (Stmt, error) {
 	if p.match(FUN) {
 		return p.function("function")
 	}
@@ -35,10 +144,125 @@ func (p *Parser) declaration() (Stmt, error) {
 		return p.varDeclaration()
 	}
 
+	if p.match(ENUM) {
+		return p.enumDeclaration()
+	}
+
+	if p.match(RECORD) {
+		return p.recordDeclaration()
+	}
+
 	return p.statement()
 }
 
+// Grammar Production:
+// recordDecl → "record" IDENTIFIER "(" parameters? ")" ";" ;
+func (p *Parser) recordDeclaration() (Stmt, error) {
+	doc := p.previous().doc
+
+	name, err := p.consume(IDENTIFIER, "Expect record name.")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(LEFT_PAREN, "Expect '(' after record name.")
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []Token
+	seen := map[string]bool{}
+
+	if !p.check(RIGHT_PAREN) {
+		for {
+			field, err := p.consume(IDENTIFIER, "Expect field name.")
+			if err != nil {
+				return nil, err
+			}
+
+			if field.lexeme == recordWithMethod {
+				return nil, p.errorWithCode(ErrDuplicateName, *field, fmt.Sprintf("Record field can't be named '%s'.", recordWithMethod))
+			}
+
+			if seen[field.lexeme] {
+				return nil, p.errorWithCode(ErrDuplicateName, *field, fmt.Sprintf("Duplicate field '%s' in record %s.", field.lexeme, name.lexeme))
+			}
+			seen[field.lexeme] = true
+			fields = append(fields, *field)
+
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+
+	_, err = p.consume(RIGHT_PAREN, "Expect ')' after record fields.")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(SEMICOLON, "Expect ';' after record declaration.")
+	if err != nil {
+		return nil, err
+	}
+
+	return StmtRecord{
+		name:   *name,
+		fields: fields,
+		doc:    doc,
+	}, nil
+}
+
+// Grammar Production:
+// enumDecl → "enum" IDENTIFIER "{" ( IDENTIFIER ( "," IDENTIFIER )* ","? )? "}" ;
+func (p *Parser) enumDeclaration() (Stmt, error) {
+	doc := p.previous().doc
+
+	name, err := p.consume(IDENTIFIER, "Expect enum name.")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(LEFT_BRACE, "Expect '{' before enum body.")
+	if err != nil {
+		return nil, err
+	}
+
+	var members []Token
+	seen := map[string]bool{}
+
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		member, err := p.consume(IDENTIFIER, "Expect enum member name.")
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[member.lexeme] {
+			return nil, p.errorWithCode(ErrDuplicateName, *member, fmt.Sprintf("Duplicate member '%s' in enum %s.", member.lexeme, name.lexeme))
+		}
+		seen[member.lexeme] = true
+		members = append(members, *member)
+
+		if !p.match(COMMA) {
+			break
+		}
+	}
+
+	_, err = p.consume(RIGHT_BRACE, "Expect '}' after enum body.")
+	if err != nil {
+		return nil, err
+	}
+
+	return StmtEnum{
+		name:    *name,
+		members: members,
+		doc:     doc,
+	}, nil
+}
+
 func (p *Parser) function(kind string) (Stmt, error) {
+	doc := p.previous().doc
+
 	name, err := p.consume(IDENTIFIER, fmt.Sprintf("Expect %s name", kind))
 	if err != nil {
 		return nil, err
@@ -61,7 +285,7 @@ func (p *Parser) function(kind string) (Stmt, error) {
 
 		for p.match(COMMA) {
 			if len(parameters) >= 255 {
-				return nil, p.error(p.peek(), "Can't have more than 255 parameters.")
+				return nil, p.errorWithCode(ErrTooManyArguments, p.peek(), "Can't have more than 255 parameters.")
 			}
 
 			newParam, err := p.consume(IDENTIFIER, "Expect parameter name.")
@@ -83,7 +307,9 @@ func (p *Parser) function(kind string) (Stmt, error) {
 		return nil, err
 	}
 
+	p.functionDepth++
 	body, err := p.blockStatement()
+	p.functionDepth--
 	if err != nil {
 		return nil, err
 	}
@@ -92,10 +318,13 @@ func (p *Parser) function(kind string) (Stmt, error) {
 		name:   *name,
 		params: parameters,
 		body:   body.(StmtBlock),
+		doc:    doc,
 	}, nil
 }
 
 func (p *Parser) varDeclaration() (Stmt, error) {
+	doc := p.previous().doc
+
 	name, err := p.consume(IDENTIFIER, "Expect variable name.")
 	if err != nil {
 		return nil, err
@@ -110,9 +339,14 @@ func (p *Parser) varDeclaration() (Stmt, error) {
 	}
 
 	_, err = p.consume(SEMICOLON, "Expect ';' after variable declaration.")
+	if err != nil {
+		return nil, err
+	}
+
 	return StmtVar{
 		name:        *name,
 		initializer: initializer,
+		doc:         doc,
 	}, nil
 }
 
@@ -125,6 +359,10 @@ func (p *Parser) statement() (Stmt, error) {
 		return p.ifStatement()
 	}
 
+	if p.match(ASSERT) {
+		return p.assertStatement()
+	}
+
 	if p.match(PRINT) {
 		return p.printStatement()
 	}
@@ -145,6 +383,8 @@ func (p *Parser) statement() (Stmt, error) {
 }
 
 func (p *Parser) forStatement() (Stmt, error) {
+	keyword := p.previous()
+
 	_, err := p.consume(LEFT_PAREN, "Expect '(' after 'for'.")
 	if err != nil {
 		return nil, err
@@ -180,6 +420,10 @@ func (p *Parser) forStatement() (Stmt, error) {
 	}
 
 	_, err = p.consume(SEMICOLON, "Expect ';' after loop condition.")
+	if err != nil {
+		return nil, err
+	}
+
 	var increment Expr
 	if !p.check(RIGHT_PAREN) {
 		v, err := p.expression()
@@ -219,6 +463,7 @@ func (p *Parser) forStatement() (Stmt, error) {
 	}
 
 	body = StmtWhile{
+		keyword:   keyword,
 		condition: condition,
 		body:      body,
 	}
@@ -290,6 +535,10 @@ func (p *Parser) printStatement() (Stmt, error) {
 
 func (p *Parser) returnStatement() (Stmt, error) {
 	returnKeyword := p.previous()
+	if p.functionDepth == 0 {
+		return nil, p.errorWithCode(ErrReturnOutsideFunction, returnKeyword, "Can't return from top-level code.")
+	}
+
 	var value Expr
 	if !p.check(SEMICOLON) {
 		var err error
@@ -310,6 +559,39 @@ func (p *Parser) returnStatement() (Stmt, error) {
 	}, nil
 }
 
+// Grammar Production:
+// assertStmt → "assert" expression ( "," expression )? ";" ;
+func (p *Parser) assertStatement() (Stmt, error) {
+	keyword := p.previous()
+
+	start := p.current
+	condition, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	source := p.text(p.Tokens[start:p.current])
+
+	var message Expr
+	if p.match(COMMA) {
+		message, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.consume(SEMICOLON, "Expect ';' after assertion.")
+	if err != nil {
+		return nil, err
+	}
+
+	return StmtAssert{
+		keyword:   keyword,
+		condition: condition,
+		message:   message,
+		source:    source,
+	}, nil
+}
+
 func (p *Parser) expressionStatement() (Stmt, error) {
 	expr, err := p.expression()
 	if err != nil {
@@ -324,6 +606,8 @@ func (p *Parser) expressionStatement() (Stmt, error) {
 }
 
 func (p *Parser) whileStatement() (Stmt, error) {
+	keyword := p.previous()
+
 	_, err := p.consume(LEFT_PAREN, "Expect '(' after 'while'.")
 	if err != nil {
 		return nil, err
@@ -344,8 +628,8 @@ func (p *Parser) whileStatement() (Stmt, error) {
 		return nil, err
 	}
 
-	fmt.Println(body)
 	return StmtWhile{
+		keyword:   keyword,
 		condition: condition,
 		body:      body,
 	}, nil
@@ -357,7 +641,8 @@ func (p *Parser) blockStatement() (Stmt, error) {
 	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
 		s, err := p.declaration()
 		if err != nil {
-			return nil, err
+			// already synchronized, so carry on with the rest of the block
+			continue
 		}
 
 		statements = append(statements, s)
@@ -390,17 +675,22 @@ func (p *Parser) assignment() (Expr, error) {
 			return nil, err
 		}
 
-		isVar := reflect.TypeOf(expr).String() == "main.Var"
-
-		if isVar {
-			name := expr.(Var).name
+		if v, ok := expr.(Var); ok {
 			return Assign{
-				name:  name,
+				name:  v.name,
 				value: value,
 			}, nil
 		}
 
-		return nil, p.error(eq, "Invalid assignment target.")
+		if get, ok := expr.(Get); ok {
+			return Set{
+				object: get.object,
+				name:   get.name,
+				value:  value,
+			}, nil
+		}
+
+		return nil, p.errorWithCode(ErrInvalidAssignmentTarget, eq, "Invalid assignment target.")
 	}
 
 	return expr, nil
@@ -570,6 +860,9 @@ func (p *Parser) unary() (Expr, error) {
 
 func (p *Parser) call() (Expr, error) {
 	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
 
 	for {
 		if p.match(LEFT_PAREN) {
@@ -577,6 +870,16 @@ func (p *Parser) call() (Expr, error) {
 			if err != nil {
 				return nil, err
 			}
+		} else if p.match(DOT) {
+			name, err := p.consume(IDENTIFIER, "Expect property name after '.'.")
+			if err != nil {
+				return nil, err
+			}
+
+			expr = Get{
+				object: expr,
+				name:   *name,
+			}
 		} else {
 			break
 		}
@@ -585,28 +888,60 @@ func (p *Parser) call() (Expr, error) {
 	return expr, nil
 }
 
-func (p *Parser) finishCall(expr Expr) (Expr, error) {
+// NamedArgument is a `name: value` argument in a call expression.
+type NamedArgument struct {
+	name  Token
+	value Expr
+}
+
+// Grammar Production:
+// arguments → ( expression | IDENTIFIER ":" expression ) ( "," ( expression | IDENTIFIER ":" expression ) )* ;
+// Named arguments must come after any positional ones.
+func (p *Parser) finishCall(callee Expr) (Expr, error) {
 	var arguments []Expr
+	var namedArguments []NamedArgument
+	seen := map[string]bool{}
 
 	if !p.check(RIGHT_PAREN) {
-		expr, err := p.expression()
-		if err != nil {
-			return nil, err
-		}
-
-		arguments = append(arguments, expr)
-
-		for p.match(COMMA) {
-			if len(arguments) >= 255 {
-				p.error(p.peek(), "Can't have more than 255 arguments.")
+		for {
+			if len(arguments)+len(namedArguments) >= 255 {
+				return nil, p.errorWithCode(ErrTooManyArguments, p.peek(), "Can't have more than 255 arguments.")
 			}
 
-			expr, err := p.expression()
-			if err != nil {
-				return nil, err
+			if p.check(IDENTIFIER) && p.checkNext(COLON) {
+				name := p.advance()
+				p.advance()
+
+				if seen[name.lexeme] {
+					return nil, p.errorWithCode(ErrDuplicateName, name, fmt.Sprintf("Duplicate named argument '%s'.", name.lexeme))
+				}
+				seen[name.lexeme] = true
+
+				value, err := p.expression()
+				if err != nil {
+					return nil, err
+				}
+
+				namedArguments = append(namedArguments, NamedArgument{
+					name:  name,
+					value: value,
+				})
+			} else {
+				if len(namedArguments) > 0 {
+					return nil, p.error(p.peek(), "Positional arguments must come before named arguments.")
+				}
+
+				expr, err := p.expression()
+				if err != nil {
+					return nil, err
+				}
+
+				arguments = append(arguments, expr)
 			}
 
-			arguments = append(arguments, expr)
+			if !p.match(COMMA) {
+				break
+			}
 		}
 	}
 
@@ -616,9 +951,10 @@ func (p *Parser) finishCall(expr Expr) (Expr, error) {
 	}
 
 	return Call{
-		callee:    expr,
-		arguments: arguments,
-		paren:     *paren,
+		callee:         callee,
+		arguments:      arguments,
+		namedArguments: namedArguments,
+		paren:          *paren,
 	}, nil
 }
 
@@ -661,6 +997,7 @@ func (p *Parser) primary() (Expr, error) {
 }
 
 func (p *Parser) peek() Token {
+	p.fill(p.current)
 	return p.Tokens[p.current]
 }
 
@@ -686,6 +1023,39 @@ func (p *Parser) check(tokenType TokenType) bool {
 	return p.peek().tokenType == tokenType
 }
 
+// checkNext looks at the token after the current one without consuming anything.
+func (p *Parser) checkNext(tokenType TokenType) bool {
+	if p.isAtEnd() || !p.fill(p.current+1) {
+		return false
+	}
+
+	return p.Tokens[p.current+1].tokenType == tokenType
+}
+
+// fill reads tokens from the tokenSource, if there is one, until Tokens has one at idx.
+// A read error is recorded and treated as the end of the input.
+func (p *Parser) fill(idx int) bool {
+	for len(p.Tokens) <= idx {
+		if p.tokenSource == nil {
+			return false
+		}
+
+		if len(p.Tokens) > 0 && p.Tokens[len(p.Tokens)-1].tokenType == EOF {
+			return false
+		}
+
+		token, err := p.tokenSource.Next()
+		if err != nil {
+			p.readErr = err
+			token = Token{tokenType: EOF}
+		}
+
+		p.Tokens = append(p.Tokens, token)
+	}
+
+	return true
+}
+
 func (p *Parser) advance() Token {
 	if !p.isAtEnd() {
 		p.current++
@@ -708,10 +1078,17 @@ func (p *Parser) isAtEnd() bool {
 }
 
 // error handling code
+
+// ParseError is returned while unwinding from a syntax error.
+// Parse itself returns Diagnostics, whose entries all have ParseError as their Err.
 var ParseError = errors.New("parse error")
 
 func (p *Parser) error(token Token, message string) error {
-	p.Lox.error(token, message)
+	return p.errorWithCode(ErrSyntax, token, message)
+}
+
+func (p *Parser) errorWithCode(code ErrorCode, token Token, message string) error {
+	p.diagnostics = append(p.diagnostics, p.Lox.error(code, token, message))
 	return ParseError
 }
 
@@ -721,13 +1098,16 @@ func (p *Parser) isAtEndOfPreviousStatement() bool {
 
 // map of keywords which start a statement
 var statementStarterKeywords = map[TokenType]bool{
+	ASSERT: true,
 	CLASS:  true,
+	ENUM:   true,
 	FUN:    true,
 	VAR:    true,
 	FOR:    true,
 	IF:     true,
 	WHILE:  true,
 	PRINT:  true,
+	RECORD: true,
 	RETURN: true,
 }
 
@@ -746,3 +1126,22 @@ func (p *Parser) synchronize() {
 		p.advance()
 	}
 }
+
+// text is the source code spanned by tokens, as it was written.
+func (p *Parser) text(tokens []Token) string {
+	start, end := tokens[0].start, tokens[len(tokens)-1].end
+
+	if keeper, ok := p.tokenSource.(sourceKeeper); ok {
+		return keeper.text(start, end)
+	}
+	if end <= len(p.Source) {
+		return p.Source[start:end]
+	}
+
+	// without the Source, all that's left are the tokens themselves
+	lexemes := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		lexemes = append(lexemes, t.lexeme)
+	}
+	return strings.Join(lexemes, " ")
+}
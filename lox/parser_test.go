@@ -1,6 +1,9 @@
-package main
+package lox
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
 
 	is2 "github.com/matryer/is"
@@ -38,8 +41,11 @@ func TestParser_Parse(t *testing.T) {
 				lexeme:    "foo",
 				literal:   nil,
 				line:      0,
+				column:    5,
+				start:     4,
+				end:       7,
 			},
-			initializer: Literal{value: 20},
+			initializer: Literal{value: 20.0},
 		}})
 }
 
@@ -75,6 +81,9 @@ func TestParser_ParseFunctionDeclaration(t *testing.T) {
 				lexeme:    "myFun",
 				literal:   nil,
 				line:      0,
+				column:    5,
+				start:     4,
+				end:       9,
 			},
 			params: []Token{
 				{
@@ -82,12 +91,18 @@ func TestParser_ParseFunctionDeclaration(t *testing.T) {
 					lexeme:    "a",
 					literal:   nil,
 					line:      0,
+					column:    11,
+					start:     10,
+					end:       11,
 				},
 				{
 					tokenType: IDENTIFIER,
 					lexeme:    "b",
 					literal:   nil,
 					line:      0,
+					column:    14,
+					start:     13,
+					end:       14,
 				},
 			},
 			body: StmtBlock{
@@ -99,6 +114,9 @@ func TestParser_ParseFunctionDeclaration(t *testing.T) {
 								lexeme:    "+",
 								literal:   nil,
 								line:      0,
+								column:    21,
+								start:     20,
+								end:       21,
 							},
 							left: Var{
 								name: Token{
@@ -106,6 +124,9 @@ func TestParser_ParseFunctionDeclaration(t *testing.T) {
 									lexeme:    "a",
 									literal:   nil,
 									line:      0,
+									column:    19,
+									start:     18,
+									end:       19,
 								},
 							},
 							right: Var{
@@ -114,6 +135,9 @@ func TestParser_ParseFunctionDeclaration(t *testing.T) {
 									lexeme:    "b",
 									literal:   nil,
 									line:      0,
+									column:    23,
+									start:     22,
+									end:       23,
 								},
 							},
 						},
@@ -156,6 +180,9 @@ func TestParser_ParseReturnStatementWithExpression(t *testing.T) {
 				lexeme:    "myFun",
 				literal:   nil,
 				line:      0,
+				column:    5,
+				start:     4,
+				end:       9,
 			},
 			params: nil,
 			body: StmtBlock{
@@ -166,6 +193,9 @@ func TestParser_ParseReturnStatementWithExpression(t *testing.T) {
 							lexeme:    "return",
 							literal:   nil,
 							line:      0,
+							column:    15,
+							start:     14,
+							end:       20,
 						},
 						value: Binary{
 							left: Literal{
@@ -176,6 +206,9 @@ func TestParser_ParseReturnStatementWithExpression(t *testing.T) {
 								lexeme:    "+",
 								literal:   nil,
 								line:      0,
+								column:    24,
+								start:     23,
+								end:       24,
 							},
 							right: Literal{
 								value: 2.0,
@@ -222,6 +255,9 @@ func TestParser_ParseReturnStatementWithNoReturnValue(t *testing.T) {
 				lexeme:    "myFun",
 				literal:   nil,
 				line:      0,
+				column:    5,
+				start:     4,
+				end:       9,
 			},
 			params: nil,
 			body: StmtBlock{
@@ -232,6 +268,9 @@ func TestParser_ParseReturnStatementWithNoReturnValue(t *testing.T) {
 							lexeme:    "return",
 							literal:   nil,
 							line:      0,
+							column:    15,
+							start:     14,
+							end:       20,
 						},
 						value: nil,
 					},
@@ -241,3 +280,154 @@ func TestParser_ParseReturnStatementWithNoReturnValue(t *testing.T) {
 
 	is.Equal(statements, expected)
 }
+
+func TestParser_ParseReturnAtTopLevel(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	scanner := Scanner{
+		lox:    &lox,
+		source: "return 1;",
+	}
+
+	tokens, err := scanner.scanTokens()
+	is.NoErr(err)
+
+	parser := Parser{
+		Lox:    &lox,
+		Tokens: tokens,
+	}
+
+	_, err = parser.Parse()
+
+	is.True(errors.Is(err, ParseError))
+	is.True(lox.hadError)
+}
+
+func TestParser_ParseReportsEverySyntaxError(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	scanner := Scanner{
+		lox:    &lox,
+		source: "var a = ;\nprint 1;\nfun f() { var = 2; print 3; }\nprint (4;\nvar ok = 5;\n",
+		line:   1,
+	}
+
+	tokens, err := scanner.scanTokens()
+	is.NoErr(err)
+
+	parser := Parser{
+		Lox:    &lox,
+		Tokens: tokens,
+	}
+
+	statements, err := parser.Parse()
+
+	is.Equal(statements, nil) // a program with errors is never returned
+	is.True(errors.Is(err, ParseError))
+
+	var parseErrors Diagnostics
+	is.True(errors.As(err, &parseErrors))
+
+	var lines []int
+	for _, d := range parseErrors {
+		lines = append(lines, d.Line)
+	}
+	is.Equal(lines, []int{1, 3, 4})
+	is.Equal(parseErrors[1].Message, "Expect variable name.")
+	is.Equal(parseErrors[2].Message, "Expect ')' after expression.")
+}
+
+func TestParser_NextStreamsDeclarations(t *testing.T) {
+	is := is2.New(t)
+
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		sb.WriteString("var x = 1 + 2;\n")
+	}
+
+	lox := Lox{}
+	parser := NewStreamingParser(&lox, NewScanner(&lox, strings.NewReader(sb.String())))
+
+	count := 0
+	for {
+		stmt, err := parser.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		is.NoErr(err)
+		_, ok := stmt.(StmtVar)
+		is.True(ok)
+		count++
+
+		// only the tokens of the current declaration are kept
+		is.True(len(parser.Tokens) < 10)
+	}
+	is.Equal(count, 1000)
+}
+
+func TestParser_NextCarriesOnAfterSyntaxError(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	parser := NewStreamingParser(&lox, NewScanner(&lox, strings.NewReader("print 1; print +; print 2;")))
+
+	_, err := parser.Next()
+	is.NoErr(err)
+
+	_, err = parser.Next()
+	is.True(errors.Is(err, ParseError))
+
+	stmt, err := parser.Next()
+	is.NoErr(err)
+	_, ok := stmt.(StmtPrint)
+	is.True(ok)
+
+	_, err = parser.Next()
+	is.True(errors.Is(err, io.EOF))
+}
+
+func TestParser_ParseDocComments(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	scanner := Scanner{
+		lox: &lox,
+		source: `
+/// The answer.
+var answer = 42;
+
+/**
+ * Adds one.
+ */
+fun inc(n) { return n + 1; }
+
+/// Primary colours.
+enum Color { Red, Green, Blue }
+
+/// A point on the plane.
+record Point(x, y);
+
+var undocumented;
+`,
+	}
+
+	tokens, err := scanner.scanTokens()
+	is.NoErr(err)
+
+	parser := Parser{
+		Lox:    &lox,
+		Tokens: tokens,
+	}
+
+	statements, err := parser.Parse()
+	is.NoErr(err)
+	is.Equal(len(statements), 5)
+
+	is.Equal(statements[0].(StmtVar).doc, "The answer.")
+	is.Equal(statements[1].(StmtFunction).doc, "Adds one.")
+	is.Equal(statements[2].(StmtEnum).doc, "Primary colours.")
+	is.Equal(statements[3].(StmtRecord).doc, "A point on the plane.")
+	is.Equal(statements[4].(StmtVar).doc, "")
+}
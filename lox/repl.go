@@ -0,0 +1,217 @@
+package lox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// RunPrompt runs an interactive session, reading and running a line at a time from the Engine's Stdin.
+// Every line runs in the same interpreter, so variables and functions declared on one line can be used
+// on the next, and a line which is a bare expression, like `1 + 2`, has its value printed. Input with
+// brackets or braces left open, or a string or comment not closed, carries on over the following lines.
+// Lines starting with a colon are commands, type :help to list them.
+// The prompt is written to the Engine's Stdout, and errors are reported to its Stderr.
+//
+// When Stdin and Stdout are a terminal, lines can be edited as they're typed, and earlier ones recalled
+// with the arrow keys or searched for with Ctrl-R. They're saved to the Engine's history file.
+func (e *Engine) RunPrompt() error {
+	r := repl{
+		engine:         e,
+		interpreter:    e.interpreter(),
+		initialGlobals: map[string]any{},
+	}
+	for name, value := range e.globals.Values {
+		r.initialGlobals[name] = value
+	}
+	reader := e.lineReader()
+
+	for {
+		line, err := reader.readLine(r.prompt())
+		if errors.Is(err, errInterrupted) {
+			r.pending = ""
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		r.input(line)
+	}
+
+	// Print an additional line if we encountered an EOF character
+	_, _ = fmt.Fprintln(e.lox.stdout())
+	return nil
+}
+
+// lineReader is a lineEditor if RunPrompt is being used from a terminal, or a plainReader if not.
+func (e *Engine) lineReader() lineReader {
+	in, inOk := e.lox.stdin().(*os.File)
+	out, outOk := e.lox.stdout().(*os.File)
+	if !inOk || !outOk || !isTerminal(in) || !isTerminal(out) {
+		return plainReader{
+			scanner: bufio.NewScanner(e.lox.stdin()),
+			out:     e.lox.stdout(),
+		}
+	}
+
+	fd := int(in.Fd())
+	raw := func() (func() error, error) {
+		return makeRaw(fd)
+	}
+	restore, err := raw()
+	if err != nil {
+		return plainReader{
+			scanner: bufio.NewScanner(in),
+			out:     out,
+		}
+	}
+	_ = restore()
+
+	h, err := loadHistory(e.historyFile)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.lox.stderr(), "warning: history won't be saved: %s\n", err)
+		h = &history{}
+	}
+
+	return &lineEditor{
+		in:      bufio.NewReader(in),
+		out:     out,
+		history: h,
+		makeRaw: raw,
+	}
+}
+
+// repl is an interactive session, see Engine.RunPrompt.
+type repl struct {
+	engine *Engine
+	// interpreter is kept for the whole session, along with the Engine's global environment,
+	// but each line gets a fresh budget, as each Run does
+	interpreter Interpreter
+	// pending is input which isn't complete yet, waiting for the lines which finish it
+	pending string
+	// initialGlobals are the global variables the session started with, which :reset goes back to
+	initialGlobals map[string]any
+}
+
+func (r *repl) prompt() string {
+	if r.pending != "" {
+		return "... "
+	}
+
+	return "> "
+}
+
+// input handles a line typed into the REPL, running it once it completes the input.
+func (r *repl) input(line string) {
+	if r.pending == "" && strings.HasPrefix(strings.TrimSpace(line), ":") {
+		r.command(line)
+		return
+	}
+
+	source := line
+	if r.pending != "" {
+		source = r.pending + "\n" + line
+	}
+
+	if isIncomplete(source) {
+		r.pending = source
+		return
+	}
+
+	r.pending = ""
+	r.runLine(source)
+}
+
+func (r *repl) runLine(line string) {
+	r.resetBudget()
+
+	if expr, ok := parseExpression(line); ok {
+		r.engine.lox.source = line
+		r.engine.lox.file = ""
+
+		if _, err := r.interpreter.InterpretExpression(expr); err != nil {
+			r.engine.Render(r.engine.lox.runtimeDiagnostics(err))
+		}
+		return
+	}
+
+	program, err := r.engine.Compile(line)
+	if err == nil {
+		err = r.engine.run(context.Background(), r.interpreter, program)
+	}
+	if err != nil {
+		r.engine.Render(err)
+	}
+}
+
+// resetBudget gives the interpreter a fresh budget for the Engine's Limits, keeping its environment.
+func (r *repl) resetBudget() {
+	r.interpreter = r.interpreter.WithLimits(r.engine.limits)
+}
+
+// isIncomplete reports whether source stops partway through, with brackets or braces left open,
+// or a string or comment not closed, so the REPL should read more lines before running it.
+func isIncomplete(source string) bool {
+	l := &Lox{}
+	scanner := Scanner{
+		lox:    l,
+		source: source,
+		line:   1,
+	}
+	tokens, err := scanner.scanTokens()
+	if err != nil {
+		return false
+	}
+
+	for _, d := range l.diagnostics {
+		if d.Code == ErrUnterminatedString || d.Code == ErrUnterminatedComment {
+			return true
+		}
+	}
+
+	depth := 0
+	for _, token := range tokens {
+		switch token.tokenType {
+		case LEFT_PAREN, LEFT_BRACE:
+			depth++
+		case RIGHT_PAREN, RIGHT_BRACE:
+			depth--
+		}
+	}
+
+	return depth > 0
+}
+
+// parseExpression parses line if it's a single expression, with no semicolon after it.
+// Lines are tried as expressions before statements, so any errors here are thrown away.
+func parseExpression(line string) (Expr, bool) {
+	l := &Lox{}
+	scanner := Scanner{
+		lox:    l,
+		source: line,
+		line:   1,
+	}
+	tokens, err := scanner.scanTokens()
+	if err != nil || l.hadError {
+		return nil, false
+	}
+
+	parser := Parser{
+		Lox:    l,
+		Tokens: tokens,
+	}
+	expr, err := parser.ParseExpression()
+	if err != nil {
+		return nil, false
+	}
+
+	return expr, true
+}
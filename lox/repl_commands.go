@@ -0,0 +1,172 @@
+package lox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// replCommand is a command typed into the REPL as a colon, its name, and an argument if it takes one,
+// like `:ast 1 + 2`.
+type replCommand struct {
+	name string
+	// arg describes the argument the command takes, or is "" if it doesn't take one
+	arg  string
+	help string
+	run  func(r *repl, arg string)
+}
+
+// commands are the REPL's commands, in the order :help lists them.
+func (r *repl) commands() []replCommand {
+	return []replCommand{
+		{name: "env", help: "list the global variables and their values", run: (*repl).env},
+		{name: "tokens", arg: "<code>", help: "show the tokens code is scanned into", run: (*repl).tokens},
+		{name: "ast", arg: "<code>", help: "show the syntax tree code is parsed into", run: (*repl).ast},
+		{name: "load", arg: "<file>", help: "run a file in the current session", run: (*repl).load},
+		{name: "reset", help: "forget everything defined in the session", run: (*repl).reset},
+		{name: "time", arg: "<code>", help: "run code and show how long it took", run: (*repl).time},
+		{name: "help", help: "list the commands", run: (*repl).help},
+	}
+}
+
+// command runs a line which starts with a colon.
+func (r *repl) command(line string) {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), ":"), " ")
+	arg = strings.TrimSpace(arg)
+
+	for _, command := range r.commands() {
+		if command.name != name {
+			continue
+		}
+
+		if command.arg != "" && arg == "" {
+			r.printError(fmt.Sprintf("usage: :%s %s", command.name, command.arg))
+			return
+		}
+		if command.arg == "" && arg != "" {
+			r.printError(fmt.Sprintf("usage: :%s", command.name))
+			return
+		}
+
+		command.run(r, arg)
+		return
+	}
+
+	r.printError(fmt.Sprintf("unknown command ':%s', type :help for a list of commands", name))
+}
+
+func (r *repl) env(string) {
+	globals := r.engine.globals
+
+	names := make([]string, 0, len(globals.Values))
+	for name := range globals.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.println(fmt.Sprintf("%s = %s", name, stringify(globals.Values[name])))
+	}
+}
+
+func (r *repl) tokens(code string) {
+	l := &Lox{}
+	scanner := Scanner{
+		lox:    l,
+		source: code,
+		line:   1,
+	}
+	tokens, err := scanner.scanTokens()
+	if err != nil {
+		r.printError(err.Error())
+		return
+	}
+	if l.hadError {
+		NewDiagnosticRenderer(r.engine.lox.stderr(), code).RenderError(Diagnostics(l.diagnostics))
+		return
+	}
+
+	for _, token := range tokens {
+		s := fmt.Sprintf("%d:%d %s %q", token.line, token.column, token.tokenType, token.lexeme)
+		if token.literal != nil {
+			s += " " + stringify(token.literal)
+		}
+		r.println(s)
+	}
+}
+
+func (r *repl) ast(code string) {
+	printer := AstPrinter{}
+
+	if expr, ok := parseExpression(code); ok {
+		r.println(printer.Print(expr))
+		return
+	}
+
+	program, err := r.engine.Compile(code)
+	if err != nil {
+		r.engine.Render(err)
+		return
+	}
+
+	for _, stmt := range program.statements {
+		r.println(printer.PrintStmt(stmt))
+	}
+}
+
+func (r *repl) load(path string) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		r.printError(err.Error())
+		return
+	}
+
+	program, err := r.engine.CompileSource(path, string(bytes))
+	if err == nil {
+		r.resetBudget()
+		err = r.engine.run(context.Background(), r.interpreter, program)
+	}
+	if err != nil {
+		r.engine.Render(err)
+	}
+}
+
+// reset puts the global environment back the way it was when the session started, with anything
+// defined by the Engine's embedder, but none of what was defined in the session.
+func (r *repl) reset(string) {
+	values := make(map[string]any, len(r.initialGlobals))
+	for name, value := range r.initialGlobals {
+		values[name] = value
+	}
+
+	r.engine.globals.Values = values
+	r.interpreter = r.engine.interpreter()
+	r.pending = ""
+}
+
+func (r *repl) time(code string) {
+	start := time.Now()
+	r.runLine(code)
+	r.println(fmt.Sprintf("took %s", time.Since(start)))
+}
+
+func (r *repl) help(string) {
+	for _, command := range r.commands() {
+		usage := ":" + command.name
+		if command.arg != "" {
+			usage += " " + command.arg
+		}
+		r.println(fmt.Sprintf("%-16s %s", usage, command.help))
+	}
+}
+
+func (r *repl) println(s string) {
+	_, _ = fmt.Fprintln(r.engine.lox.stdout(), s)
+}
+
+func (r *repl) printError(s string) {
+	_, _ = fmt.Fprintf(r.engine.lox.stderr(), "error: %s\n", s)
+}
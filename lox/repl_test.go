@@ -0,0 +1,225 @@
+package lox
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestRepl(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "statements",
+			input:      "print 1 + 2;\n",
+			wantStdout: "> 3\n> \n",
+		},
+		{
+			name:       "bare expressions print their value",
+			input:      "1 + 2\n\"lox\"\nnil\n",
+			wantStdout: "> 3\n> lox\n> nil\n> \n",
+		},
+		{
+			name:       "declarations are kept between lines",
+			input:      "var a = 1;\nfun double(n) { return n * 2; }\ndouble(a)\na = 5\nprint double(a);\n",
+			wantStdout: "> > > 2\n> 5\n> 10\n> \n",
+		},
+		{
+			name:       "blank lines",
+			input:      "\n\n",
+			wantStdout: "> > > \n",
+		},
+		{
+			name:       "syntax errors don't end the session",
+			input:      "print 1 +;\nprint 2;\n",
+			wantStdout: "> > 2\n> \n",
+			wantStderr: "error[E0010]: Expect expression.",
+		},
+		{
+			name:       "unclosed braces continue on the next line",
+			input:      "fun add(a, b) {\n  return a + b;\n}\nadd(1,\n2)\n",
+			wantStdout: "> ... ... > ... 3\n> \n",
+		},
+		{
+			name:       "unterminated strings continue on the next line",
+			input:      "print \"a\nb\";\n",
+			wantStdout: "> ... a\nb\n> \n",
+		},
+		{
+			name:       "runtime errors don't end the session",
+			input:      "1 / 0\nvar a = 1;\nprint b;\na\n",
+			wantStdout: "> > > > 1\n> \n",
+			wantStderr: "error[E0012]: Undefined variable 'b'.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			var stdout, stderr bytes.Buffer
+			engine := NewEngine(WithStdin(strings.NewReader(tt.input)), WithStdout(&stdout), WithStderr(&stderr))
+
+			err := engine.RunPrompt()
+			is.NoErr(err)
+			is.Equal(stdout.String(), tt.wantStdout)
+			is.True(strings.Contains(stderr.String(), tt.wantStderr))
+		})
+	}
+}
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"print 1;", false},
+		{"fun f() {", true},
+		{"fun f() {\n}", false},
+		{"print (1 +", true},
+		{"print \"abc", true},
+		{"print \"\"\"abc", true},
+		{"/* comment", true},
+		{"}", false},
+		{"print 1 +;", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(isIncomplete(tt.source), tt.want)
+		})
+	}
+}
+
+func TestRepl_Commands(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "script.lox")
+	if err := os.WriteFile(script, []byte("var loaded = 42;\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		input      string
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "env",
+			input:      "var b = 2;\nvar a = \"one\";\n:env\n",
+			wantStdout: "> > > a = one\nb = 2\nclock = <native fn clock>\ngreeting = hello\n> \n",
+		},
+		{
+			name:       "tokens",
+			input:      ":tokens print 1;\n",
+			wantStdout: "> 1:1 PRINT \"print\"\n1:7 NUMBER \"1\" 1\n1:8 SEMICOLON \";\"\n1:9 EOF \"\"\n> \n",
+		},
+		{
+			name:       "tokens with errors",
+			input:      ":tokens @\n",
+			wantStdout: "> > \n",
+			wantStderr: "error[E0001]",
+		},
+		{
+			name:       "ast of an expression",
+			input:      ":ast f(1 + 2)\n",
+			wantStdout: "> (call f (+ 1 2))\n> \n",
+		},
+		{
+			name:       "ast of statements",
+			input:      ":ast var a = 1; print a;\n",
+			wantStdout: "> (var a 1)\n(print a)\n> \n",
+		},
+		{
+			name:       "ast doesn't run anything",
+			input:      ":ast var a = 1;\nprint a;\n",
+			wantStdout: "> (var a 1)\n> > \n",
+			wantStderr: "Undefined variable 'a'.",
+		},
+		{
+			name:       "load",
+			input:      ":load " + script + "\nloaded\n",
+			wantStdout: "> > 42\n> \n",
+		},
+		{
+			name:       "load a missing file",
+			input:      ":load missing.lox\n",
+			wantStdout: "> > \n",
+			wantStderr: "error: open missing.lox",
+		},
+		{
+			name:       "reset keeps globals from the embedder",
+			input:      "var a = 1;\ngreeting = \"bye\";\n:reset\ngreeting\nprint a;\n",
+			wantStdout: "> > > > hello\n> > \n",
+			wantStderr: "Undefined variable 'a'.",
+		},
+		{
+			name:       "time",
+			input:      ":time 1 + 2\n",
+			wantStdout: "> 3\ntook ",
+		},
+		{
+			name:       "help",
+			input:      ":help\n",
+			wantStdout: "> :env             list the global variables and their values\n:tokens <code>   show the tokens code is scanned into\n",
+		},
+		{
+			name:       "missing argument",
+			input:      ":ast\n",
+			wantStdout: "> > \n",
+			wantStderr: "error: usage: :ast <code>\n",
+		},
+		{
+			name:       "unknown command",
+			input:      ":quit\n",
+			wantStdout: "> > \n",
+			wantStderr: "error: unknown command ':quit', type :help for a list of commands\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			var stdout, stderr bytes.Buffer
+			engine := NewEngine(
+				WithStdin(strings.NewReader(tt.input)),
+				WithStdout(&stdout),
+				WithStderr(&stderr),
+				WithGlobals(map[string]any{"greeting": "hello"}),
+			)
+
+			err := engine.RunPrompt()
+			is.NoErr(err)
+			is.True(strings.HasPrefix(stdout.String(), tt.wantStdout))
+			is.True(strings.Contains(stderr.String(), tt.wantStderr))
+		})
+	}
+}
+
+func TestRepl_LimitsApplyToEachLine(t *testing.T) {
+	is := is2.New(t)
+
+	var stdout, stderr bytes.Buffer
+	engine := NewEngine(
+		WithStdin(strings.NewReader("var i = 0;\ni\ni\ni\ni\nwhile (true) {}\ni\n")),
+		WithStdout(&stdout),
+		WithStderr(&stderr),
+		WithLimits(Limits{MaxSteps: 5}),
+	)
+
+	err := engine.RunPrompt()
+	is.NoErr(err)
+
+	// only the infinite loop runs out of budget
+	is.Equal(stdout.String(), "> > 0\n> 0\n> 0\n> 0\n> > 0\n> \n")
+	is.Equal(strings.Count(stderr.String(), "error[E0031]"), 1)
+}
@@ -0,0 +1,963 @@
+package lox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Object - see how this needs to be used later
+type Object interface{}
+
+type TokenType int
+
+const (
+	// single character tokens
+
+	LEFT_PAREN TokenType = iota
+	RIGHT_PAREN
+	LEFT_BRACE
+	RIGHT_BRACE
+	COMMA
+	COLON
+	DOT
+	MINUS
+	PLUS
+	SEMICOLON
+	SLASH
+	STAR
+
+	// One or two character tokens.
+
+	BANG
+	BANG_EQUAL
+	EQUAL
+	EQUAL_EQUAL
+	GREATER
+	GREATER_EQUAL
+	LESS
+	LESS_EQUAL
+
+	// Literals.
+	IDENTIFIER
+	STRING
+	NUMBER
+
+	// Keywords.
+	AND
+	ASSERT
+	CLASS
+	ELSE
+	ENUM
+	FALSE
+	FUN
+	FOR
+	IF
+	NIL
+	OR
+	PRINT
+	RECORD
+	RETURN
+	SUPER
+	THIS
+	TRUE
+	VAR
+	WHILE
+	EOF
+)
+
+func (t TokenType) String() string {
+	switch t {
+	case LEFT_PAREN:
+		return "LEFT_PAREN"
+	case RIGHT_PAREN:
+		return "RIGHT_PAREN"
+	case LEFT_BRACE:
+		return "LEFT_BRACE"
+	case RIGHT_BRACE:
+		return "RIGHT_BRACE"
+	case COMMA:
+		return "COMMA"
+	case COLON:
+		return "COLON"
+	case DOT:
+		return "DOT"
+	case MINUS:
+		return "MINUS"
+	case PLUS:
+		return "PLUS"
+	case SEMICOLON:
+		return "SEMICOLON"
+	case SLASH:
+		return "SLASH"
+	case STAR:
+		return "STAR"
+	case BANG:
+		return "BANG"
+	case BANG_EQUAL:
+		return "BANG_EQUAL"
+	case EQUAL:
+		return "EQUAL"
+	case EQUAL_EQUAL:
+		return "EQUAL_EQUAL"
+	case GREATER:
+		return "GREATER"
+	case GREATER_EQUAL:
+		return "GREATER_EQUAL"
+	case LESS:
+		return "LESS"
+	case LESS_EQUAL:
+		return "LESS_EQUAL"
+	case IDENTIFIER:
+		return "IDENTIFIER"
+	case STRING:
+		return "STRING"
+	case NUMBER:
+		return "NUMBER"
+	case AND:
+		return "AND"
+	case ASSERT:
+		return "ASSERT"
+	case CLASS:
+		return "CLASS"
+	case ELSE:
+		return "ELSE"
+	case ENUM:
+		return "ENUM"
+	case FALSE:
+		return "FALSE"
+	case FUN:
+		return "FUN"
+	case FOR:
+		return "FOR"
+	case IF:
+		return "IF"
+	case NIL:
+		return "NIL"
+	case OR:
+		return "OR"
+	case PRINT:
+		return "PRINT"
+	case RECORD:
+		return "RECORD"
+	case RETURN:
+		return "RETURN"
+	case SUPER:
+		return "SUPER"
+	case THIS:
+		return "THIS"
+	case TRUE:
+		return "TRUE"
+	case VAR:
+		return "VAR"
+	case WHILE:
+		return "WHILE"
+	case EOF:
+		return "EOF"
+	default:
+		return ""
+	}
+}
+
+type Token struct {
+	tokenType TokenType
+	lexeme    string
+	literal   Object
+	// line and column are where the token starts. Columns count runes, starting at 1.
+	line   int
+	column int
+	// start and end are the byte offsets of the token in the source, end being exclusive
+	start int
+	end   int
+	// doc is the text of the doc comments just before the token, for the declarations which keep it
+	doc string
+}
+
+// Line is the line the token starts on, counting from 1.
+func (t Token) Line() int {
+	return t.line
+}
+
+// Column is the column the token starts at, in runes, counting from 1.
+func (t Token) Column() int {
+	return t.column
+}
+
+// Lexeme is the token's text in the source.
+func (t Token) Lexeme() string {
+	return t.lexeme
+}
+
+func (t Token) String() string {
+	return fmt.Sprintf("%s %s %s", t.tokenType, t.lexeme, t.literal)
+}
+
+var keywords = map[string]TokenType{
+	"and":    AND,
+	"assert": ASSERT,
+	"class":  CLASS,
+	"else":   ELSE,
+	"enum":   ENUM,
+	"false":  FALSE,
+	"for":    FOR,
+	"fun":    FUN,
+	"if":     IF,
+	"nil":    NIL,
+	"or":     OR,
+	"print":  PRINT,
+	"record": RECORD,
+	"return": RETURN,
+	"super":  SUPER,
+	"this":   THIS,
+	"true":   TRUE,
+	"var":    VAR,
+	"while":  WHILE,
+}
+
+type Scanner struct {
+	lox *Lox
+	// source is scanned if the Scanner wasn't given a reader with NewScanner
+	source string
+	reader *bufio.Reader
+	// tokens holds tokens which have been scanned but not yet returned by Next
+	tokens  []Token
+	start   int
+	current int
+	line    int
+
+	// lineColumn is the number of runes consumed so far on the current line
+	lineColumn int
+	// startLine and startColumn are the position of the token currently being scanned
+	startLine   int
+	startColumn int
+
+	// lexeme is the text of the token currently being scanned
+	lexeme []byte
+	// lookahead holds runes which have been read but not consumed yet, for peek and peekNext
+	lookahead []scannedRune
+	atEOF     bool
+	readErr   error
+
+	// doc holds the doc comments scanned since the last token, which are attached to the next one
+	doc string
+
+	// read is the source consumed from the offset readFrom onwards, kept when it comes from a reader
+	// so the parser can quote it, until the parser says it's done with it
+	read     []byte
+	readFrom int
+}
+
+type scannedRune struct {
+	r    rune
+	size int
+	// raw is the byte read when it wasn't valid UTF-8, so lexemes keep the source as it was
+	raw byte
+}
+
+// NewScanner creates a Scanner which reads source code from r as tokens are asked for,
+// so the whole program never has to be held in memory.
+func NewScanner(lox *Lox, r io.Reader) *Scanner {
+	return &Scanner{
+		lox:    lox,
+		reader: bufio.NewReader(r),
+		line:   1,
+	}
+}
+
+// Next scans and returns the next token, reading only as much of the source as it needs to.
+// At the end of the source it returns an EOF token, and keeps doing so if called again.
+// The error is only non-nil if reading fails; problems with the source are reported through Lox.
+func (s *Scanner) Next() (Token, error) {
+	for len(s.tokens) == 0 {
+		s.start = s.current
+		s.startLine = s.line
+		s.startColumn = s.lineColumn + 1
+		s.lexeme = s.lexeme[:0]
+
+		if s.isAtEnd() {
+			if s.readErr != nil {
+				return Token{}, s.readErr
+			}
+
+			return Token{
+				tokenType: EOF,
+				lexeme:    "",
+				literal:   nil,
+				line:      s.line,
+				column:    s.startColumn,
+				start:     s.current,
+				end:       s.current,
+			}, nil
+		}
+
+		s.scanToken()
+	}
+
+	token := s.tokens[0]
+	s.tokens = s.tokens[1:]
+	return token, nil
+}
+
+func (s *Scanner) scanTokens() ([]Token, error) {
+	var tokens []Token
+
+	for {
+		token, err := s.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+		if token.tokenType == EOF {
+			return tokens, nil
+		}
+	}
+}
+
+// newline is called after consuming a '\n', including ones inside strings and comments.
+func (s *Scanner) newline() {
+	s.line++
+	s.lineColumn = 0
+}
+
+// error reports a problem with the token currently being scanned, spanning what has been consumed of it so far.
+func (s *Scanner) error(code ErrorCode, message string) {
+	s.lox.report(Diagnostic{
+		Severity:  SeverityError,
+		Code:      code,
+		Message:   message,
+		Line:      s.startLine,
+		Column:    s.startColumn,
+		EndLine:   s.line,
+		EndColumn: s.lineColumn + 1,
+		Start:     s.start,
+		End:       s.current,
+	})
+}
+
+func (s *Scanner) isAtEnd() bool {
+	return !s.fill(1)
+}
+
+func (s *Scanner) scanToken() {
+	r := s.advance()
+	switch r {
+	case ' ':
+		break
+	case '\r':
+		break
+	case '\t':
+		break
+	case '\n':
+		s.newline()
+		break
+	case '(':
+		s.addToken(LEFT_PAREN)
+		break
+	case ')':
+		s.addToken(RIGHT_PAREN)
+		break
+	case '{':
+		s.addToken(LEFT_BRACE)
+		break
+	case '}':
+		s.addToken(RIGHT_BRACE)
+		break
+	case ',':
+		s.addToken(COMMA)
+		break
+	case ':':
+		s.addToken(COLON)
+		break
+	case '.':
+		s.addToken(DOT)
+		break
+	case '-':
+		s.addToken(MINUS)
+		break
+	case '+':
+		s.addToken(PLUS)
+		break
+	case ';':
+		s.addToken(SEMICOLON)
+		break
+	case '*':
+		s.addToken(STAR)
+		break
+	case '!':
+		if s.match('=') {
+			s.addToken(BANG_EQUAL)
+		} else {
+			s.addToken(BANG)
+		}
+		break
+	case '=':
+		if s.match('=') {
+			s.addToken(EQUAL_EQUAL)
+		} else {
+			s.addToken(EQUAL)
+		}
+		break
+	case '<':
+		if s.match('=') {
+			s.addToken(LESS_EQUAL)
+		} else {
+			s.addToken(LESS)
+		}
+		break
+	case '>':
+		if s.match('=') {
+			s.addToken(GREATER_EQUAL)
+		} else {
+			s.addToken(GREATER)
+		}
+		break
+	case '/':
+		if s.match('/') {
+			// "///" starts a doc comment, but "////" is an ordinary one
+			isDoc := s.peek() == '/' && s.peekNext() != '/'
+
+			for s.peek() != '\n' && !s.isAtEnd() {
+				s.advance()
+			}
+
+			if isDoc {
+				s.addDoc(lineDocComment(string(s.lexeme)))
+			}
+		} else if s.match('*') {
+			// "/**" starts a doc comment, but "/**/" and "/***" are ordinary ones
+			isDoc := s.peek() == '*' && s.peekNext() != '*' && s.peekNext() != '/'
+
+			if s.scanMultiLineComment() && isDoc {
+				s.addDoc(blockDocComment(string(s.lexeme)))
+			}
+		} else {
+			s.addToken(SLASH)
+		}
+	case '"':
+		if s.peek() == '"' && s.peekNext() == '"' {
+			s.advance()
+			s.advance()
+			s.scanMultiLineStringLiteral()
+		} else {
+			s.scanStringLiteral()
+		}
+		break
+	case '`':
+		s.scanRawStringLiteral()
+		break
+	default:
+		if isDigit(r) {
+			s.scanNumberLiteral()
+		} else if isAlpha(r) {
+			s.scanIdentifierOrKeyword()
+		} else if r == utf8.RuneError {
+			s.error(ErrInvalidEncoding, "Invalid UTF-8 encoding.")
+		} else {
+			s.error(ErrUnexpectedCharacter, fmt.Sprintf("Unexpected character: '%c' (%U).", r, r))
+		}
+		break
+	}
+}
+
+// isDigit only accepts ASCII digits, as those are the only ones number literals can be written with.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isAlpha reports whether r can start an identifier: any Unicode letter or '_'.
+func isAlpha(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+// isAlphaNumeric reports whether r can continue an identifier.
+// Along with letters, this allows Unicode decimal digits and combining marks, e.g. the accents in "café" written decomposed.
+func isAlphaNumeric(r rune) bool {
+	return isAlpha(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc)
+}
+
+func (s *Scanner) scanStringLiteral() {
+	for s.peek() != '"' && !s.isAtEnd() {
+		if s.advance() == '\n' {
+			s.newline()
+		}
+	}
+
+	if s.isAtEnd() {
+		s.error(ErrUnterminatedString, "Unterminated string.")
+		return
+	}
+
+	s.advance()
+
+	strVal := string(s.lexeme[1 : len(s.lexeme)-1])
+	s.addLiteralToken(STRING, strVal)
+}
+
+// scanRawStringLiteral scans a string between backticks, which can span lines. Its text is used exactly as written.
+func (s *Scanner) scanRawStringLiteral() {
+	for s.peek() != '`' && !s.isAtEnd() {
+		if s.advance() == '\n' {
+			s.newline()
+		}
+	}
+
+	if s.isAtEnd() {
+		s.error(ErrUnterminatedString, "Unterminated raw string.")
+		return
+	}
+
+	s.advance()
+
+	strVal := string(s.lexeme[1 : len(s.lexeme)-1])
+	s.addLiteralToken(STRING, strVal)
+}
+
+// scanMultiLineStringLiteral scans the rest of a string after its opening """, up to the closing """.
+// The indentation the lines have in common is removed, see trimIndent.
+func (s *Scanner) scanMultiLineStringLiteral() {
+	for !s.lookingAt(`"""`) && !s.isAtEnd() {
+		if s.advance() == '\n' {
+			s.newline()
+		}
+	}
+
+	if s.isAtEnd() {
+		s.error(ErrUnterminatedString, "Unterminated multi-line string.")
+		return
+	}
+
+	s.advance()
+	s.advance()
+	s.advance()
+
+	strVal := trimIndent(string(s.lexeme[3 : len(s.lexeme)-3]))
+	s.addLiteralToken(STRING, strVal)
+}
+
+// trimIndent tidies up the text of a multi-line string, so it can be indented along with the code around it:
+//
+//	var query = """
+//	    select *
+//	      from users
+//	    """;
+//
+// is "select *\n  from users". The line break after the opening quotes and the whitespace before the closing quotes are
+// dropped, when there's nothing else on those lines. Then the leading whitespace which all the remaining non-blank lines
+// share, including the line with the closing quotes, is removed from each of them. Blank lines become empty,
+// and \r\n line endings become \n.
+func trimIndent(text string) string {
+	lines := strings.Split(text, "\n")
+	for idx, line := range lines {
+		lines[idx] = strings.TrimSuffix(line, "\r")
+	}
+
+	// text on the same line as the opening quotes isn't indented, so it's left alone
+	first := 0
+	if isBlank(lines[0]) && len(lines) > 1 {
+		lines = lines[1:]
+	} else {
+		first = 1
+	}
+
+	last := len(lines) - 1
+	closingOnOwnLine := last >= first && isBlank(lines[last])
+
+	indent := ""
+	found := false
+	for idx := first; idx < len(lines); idx++ {
+		if isBlank(lines[idx]) && !(closingOnOwnLine && idx == last) {
+			continue
+		}
+
+		lineIndent := lines[idx][:len(lines[idx])-len(strings.TrimLeft(lines[idx], " \t"))]
+		if !found {
+			indent = lineIndent
+			found = true
+			continue
+		}
+
+		for !strings.HasPrefix(lineIndent, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+
+	for idx := first; idx < len(lines); idx++ {
+		if isBlank(lines[idx]) {
+			lines[idx] = ""
+		} else {
+			lines[idx] = strings.TrimPrefix(lines[idx], indent)
+		}
+	}
+
+	if closingOnOwnLine {
+		lines = lines[:last]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isBlank(line string) bool {
+	return strings.TrimLeft(line, " \t") == ""
+}
+
+// scanNumberLiteral scans a decimal number like 1_000, 1.5 or 1.5e-3, or an integer with a
+// 0x, 0o or 0b prefix. Digits can be separated with '_', which is ignored.
+func (s *Scanner) scanNumberLiteral() {
+	if s.lexeme[0] == '0' {
+		switch s.peek() {
+		case 'x', 'X':
+			s.scanPrefixedNumberLiteral(16, "hexadecimal")
+			return
+		case 'o', 'O':
+			s.scanPrefixedNumberLiteral(8, "octal")
+			return
+		case 'b', 'B':
+			s.scanPrefixedNumberLiteral(2, "binary")
+			return
+		}
+	}
+
+	if !s.scanDigits(10) {
+		return
+	}
+
+	if s.peek() == '.' && isDigit(s.peekNext()) {
+		s.advance()
+
+		if !s.scanDigits(10) {
+			return
+		}
+	}
+
+	if s.peek() == 'e' || s.peek() == 'E' {
+		s.advance()
+
+		if s.peek() == '+' || s.peek() == '-' {
+			s.advance()
+		}
+
+		if !isDigit(s.peek()) {
+			s.invalidNumberLiteral("Exponent has no digits.")
+			return
+		}
+
+		if !s.scanDigits(10) {
+			return
+		}
+	}
+
+	val, err := strconv.ParseFloat(strings.ReplaceAll(string(s.lexeme), "_", ""), 64)
+	if err != nil {
+		s.invalidNumberLiteral(fmt.Sprintf("Could not parse as float: %s.", s.lexeme))
+		return
+	}
+	s.addLiteralToken(NUMBER, val)
+}
+
+// scanPrefixedNumberLiteral scans the rest of an integer literal after its leading '0', e.g. 0xFF.
+func (s *Scanner) scanPrefixedNumberLiteral(base int, name string) {
+	// the x, o or b
+	s.advance()
+
+	if s.peek() == '_' {
+		s.advance()
+		s.invalidNumberLiteral("'_' must be between digits.")
+		return
+	}
+
+	if digitValue(s.peek()) >= base {
+		if isAlphaNumeric(s.peek()) {
+			s.invalidNumberLiteral(fmt.Sprintf("Invalid digit '%c' in %s literal.", s.peek(), name))
+		} else {
+			s.invalidNumberLiteral(fmt.Sprintf("%s literal has no digits.", strings.ToUpper(name[:1])+name[1:]))
+		}
+		return
+	}
+
+	if !s.scanDigits(base) {
+		return
+	}
+
+	if isAlphaNumeric(s.peek()) {
+		s.invalidNumberLiteral(fmt.Sprintf("Invalid digit '%c' in %s literal.", s.peek(), name))
+		return
+	}
+
+	// accumulated as a float rather than parsed as an integer, so large literals lose precision instead of overflowing
+	val := 0.0
+	for _, r := range string(s.lexeme[2:]) {
+		if r != '_' {
+			val = val*float64(base) + float64(digitValue(r))
+		}
+	}
+	s.addLiteralToken(NUMBER, val)
+}
+
+// scanDigits consumes digits in base, and the '_' separators between them. It expects the rune before
+// to be a digit, or the next one to be. It returns false if a separator was misplaced, after reporting it.
+func (s *Scanner) scanDigits(base int) bool {
+	for digitValue(s.peek()) < base || s.peek() == '_' {
+		if s.advance() == '_' && digitValue(s.peek()) >= base {
+			s.invalidNumberLiteral("'_' must be between digits.")
+			return false
+		}
+	}
+
+	return true
+}
+
+// invalidNumberLiteral reports a malformed number literal. The rest of it is skipped, and a
+// NUMBER token is still added so the parser doesn't report errors of its own about it.
+func (s *Scanner) invalidNumberLiteral(message string) {
+	for isAlphaNumeric(s.peek()) {
+		s.advance()
+	}
+
+	s.error(ErrInvalidNumberLiteral, message)
+	s.addLiteralToken(NUMBER, 0.0)
+}
+
+// digitValue is the value of r as a hexadecimal digit, or 16 if it isn't one.
+func digitValue(r rune) int {
+	switch {
+	case '0' <= r && r <= '9':
+		return int(r - '0')
+	case 'a' <= r && r <= 'f':
+		return int(r-'a') + 10
+	case 'A' <= r && r <= 'F':
+		return int(r-'A') + 10
+	}
+
+	return 16
+}
+
+func (s *Scanner) scanIdentifierOrKeyword() {
+	for isAlphaNumeric(s.peek()) {
+		s.advance()
+	}
+
+	kw, ok := keywords[string(s.lexeme)]
+
+	if !ok {
+		s.addToken(IDENTIFIER)
+		return
+	}
+
+	s.addToken(kw)
+}
+
+// scanMultiLineComment scans the rest of a /* */ comment, returning false if it isn't closed.
+// Comments nest, so code which already has comments in it can be commented out.
+func (s *Scanner) scanMultiLineComment() bool {
+	depth := 1
+
+	for depth > 0 {
+		if s.isAtEnd() {
+			s.error(ErrUnterminatedComment, "Unterminated multi-line comment.")
+			return false
+		}
+
+		switch {
+		case s.peek() == '/' && s.peekNext() == '*':
+			s.advance()
+			s.advance()
+			depth++
+		case s.peek() == '*' && s.peekNext() == '/':
+			s.advance()
+			s.advance()
+			depth--
+		default:
+			if s.advance() == '\n' {
+				s.newline()
+			}
+		}
+	}
+
+	return true
+}
+
+func (s *Scanner) addDoc(text string) {
+	if s.doc != "" {
+		s.doc += "\n"
+	}
+	s.doc += text
+}
+
+// lineDocComment is the text of a /// comment, without the slashes and the space after them.
+func lineDocComment(comment string) string {
+	text := strings.TrimPrefix(comment, "///")
+	text = strings.TrimPrefix(text, " ")
+	return strings.TrimRight(text, " \t\r")
+}
+
+// blockDocComment is the text of a /** */ comment. The leading "*" which each line may have
+// is removed along with the indentation before it, as are blank lines at the start and end.
+func blockDocComment(comment string) string {
+	text := strings.TrimSuffix(strings.TrimPrefix(comment, "/**"), "*/")
+
+	lines := strings.Split(text, "\n")
+	for idx, line := range lines {
+		line = strings.TrimLeft(line, " \t")
+		if idx > 0 && strings.HasPrefix(line, "*") {
+			line = strings.TrimPrefix(line[1:], " ")
+		} else {
+			line = strings.TrimPrefix(line, " ")
+		}
+		lines[idx] = strings.TrimRight(line, " \t\r")
+	}
+
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// advance consumes the next rune. start and current are byte offsets into the source,
+// so multi-byte UTF-8 characters move current along by more than one.
+func (s *Scanner) advance() rune {
+	if !s.fill(1) {
+		return '\x00'
+	}
+
+	next := s.lookahead[0]
+	s.lookahead = s.lookahead[1:]
+
+	s.current += next.size
+	s.lineColumn++
+	n := len(s.lexeme)
+	if next.r == utf8.RuneError && next.size == 1 {
+		s.lexeme = append(s.lexeme, next.raw)
+	} else {
+		s.lexeme = utf8.AppendRune(s.lexeme, next.r)
+	}
+	if s.source == "" {
+		s.read = append(s.read, s.lexeme[n:]...)
+	}
+
+	return next.r
+}
+
+// text is the source code between the byte offsets start and end, as it was written.
+func (s *Scanner) text(start int, end int) string {
+	if s.source != "" {
+		return s.source[start:end]
+	}
+
+	return string(s.read[start-s.readFrom : end-s.readFrom])
+}
+
+// discardTextBefore lets go of the source code before offset, which text won't be asked for again.
+func (s *Scanner) discardTextBefore(offset int) {
+	if offset <= s.readFrom {
+		return
+	}
+
+	s.read = append(s.read[:0], s.read[offset-s.readFrom:]...)
+	s.readFrom = offset
+}
+
+// fill reads ahead until there are at least n runes in lookahead, returning false if the source runs out first.
+func (s *Scanner) fill(n int) bool {
+	if s.reader == nil {
+		s.reader = bufio.NewReader(strings.NewReader(s.source))
+	}
+
+	for len(s.lookahead) < n {
+		if s.atEOF || s.readErr != nil {
+			return false
+		}
+
+		r, size, err := s.reader.ReadRune()
+		if err == io.EOF {
+			s.atEOF = true
+			return false
+		}
+		if err != nil {
+			s.readErr = err
+			return false
+		}
+
+		next := scannedRune{r: r, size: size}
+		if r == utf8.RuneError && size == 1 {
+			_ = s.reader.UnreadRune()
+			next.raw, _ = s.reader.ReadByte()
+		}
+
+		s.lookahead = append(s.lookahead, next)
+	}
+
+	return true
+}
+
+func (s *Scanner) addToken(t TokenType) {
+	s.addLiteralToken(t, nil)
+}
+
+func (s *Scanner) addLiteralToken(t TokenType, val interface{}) {
+	s.tokens = append(s.tokens, Token{
+		tokenType: t,
+		lexeme:    string(s.lexeme),
+		literal:   val,
+		line:      s.startLine,
+		column:    s.startColumn,
+		start:     s.start,
+		end:       s.current,
+		doc:       s.doc,
+	})
+	s.doc = ""
+}
+
+func (s *Scanner) match(r rune) bool {
+	if s.isAtEnd() {
+		return false
+	}
+	if s.peek() != r {
+		return false
+	}
+
+	s.advance()
+	return true
+}
+
+func (s *Scanner) peek() rune {
+	if !s.fill(1) {
+		return '\x00'
+	}
+
+	return s.lookahead[0].r
+}
+
+// lookingAt reports whether the source continues with text, without consuming it.
+func (s *Scanner) lookingAt(text string) bool {
+	n := utf8.RuneCountInString(text)
+	if !s.fill(n) {
+		return false
+	}
+
+	idx := 0
+	for _, r := range text {
+		if s.lookahead[idx].r != r {
+			return false
+		}
+		idx++
+	}
+
+	return true
+}
+
+func (s *Scanner) peekNext() rune {
+	if !s.fill(2) {
+		return '\x00'
+	}
+
+	return s.lookahead[1].r
+}
@@ -0,0 +1,362 @@
+package lox
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	is2 "github.com/matryer/is"
+)
+
+func TestScanner_UnicodeIdentifiersAndStrings(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	scanner := Scanner{
+		lox:    &lox,
+		source: `var café_1 = "héllo, 世界 👋"; 名前;`,
+	}
+
+	tokens, err := scanner.scanTokens()
+	is.NoErr(err)
+	is.True(!lox.hadError)
+
+	var types []TokenType
+	for _, token := range tokens {
+		types = append(types, token.tokenType)
+	}
+	is.Equal(types, []TokenType{VAR, IDENTIFIER, EQUAL, STRING, SEMICOLON, IDENTIFIER, SEMICOLON, EOF})
+
+	is.Equal(tokens[1].lexeme, "café_1")
+	is.Equal(tokens[3].literal, "héllo, 世界 👋")
+	is.Equal(tokens[5].lexeme, "名前")
+}
+
+func TestScanner_UnexpectedMultiByteCharacter(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	scanner := Scanner{
+		lox:    &lox,
+		source: "1 € 2",
+	}
+
+	tokens, err := scanner.scanTokens()
+	is.NoErr(err)
+	is.True(lox.hadError)
+
+	// the scanner skips the whole character, rather than each of its bytes
+	is.Equal(len(tokens), 3)
+	is.Equal(tokens[1].literal, 2.0)
+}
+
+func TestScanner_TokenPositions(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	scanner := Scanner{
+		lox:    &lox,
+		source: "var s = \"a\nb\"; /* one\ntwo */ é;\n  x",
+		line:   1,
+	}
+
+	tokens, err := scanner.scanTokens()
+	is.NoErr(err)
+
+	type position struct {
+		line, column, start, end int
+	}
+	var positions []position
+	for _, token := range tokens {
+		positions = append(positions, position{token.line, token.column, token.start, token.end})
+	}
+
+	is.Equal(positions, []position{
+		{1, 1, 0, 3},   // var
+		{1, 5, 4, 5},   // s
+		{1, 7, 6, 7},   // =
+		{1, 9, 8, 13},  // "a\nb" starts on line 1
+		{2, 3, 13, 14}, // ;
+		{3, 8, 29, 31}, // é is two bytes but one column
+		{3, 9, 31, 32}, // ;
+		{4, 3, 35, 36}, // x
+		{4, 4, 36, 36}, // EOF
+	})
+}
+
+func TestScanner_NextFromReader(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	// one byte at a time, so multi-byte characters are split across reads
+	scanner := NewScanner(&lox, iotest.OneByteReader(strings.NewReader("var café = \"世界\";\nprint café;")))
+
+	var tokens []Token
+	for {
+		token, err := scanner.Next()
+		is.NoErr(err)
+		tokens = append(tokens, token)
+		if token.tokenType == EOF {
+			break
+		}
+	}
+	is.True(!lox.hadError)
+
+	var types []TokenType
+	for _, token := range tokens {
+		types = append(types, token.tokenType)
+	}
+	is.Equal(types, []TokenType{VAR, IDENTIFIER, EQUAL, STRING, SEMICOLON, PRINT, IDENTIFIER, SEMICOLON, EOF})
+
+	is.Equal(tokens[1].lexeme, "café")
+	is.Equal(tokens[3].literal, "世界")
+	is.Equal(tokens[6].line, 2)
+	is.Equal(tokens[6].column, 7)
+
+	// EOF is returned again on later calls
+	token, err := scanner.Next()
+	is.NoErr(err)
+	is.Equal(token.tokenType, EOF)
+}
+
+func TestScanner_NextReturnsReadErrors(t *testing.T) {
+	is := is2.New(t)
+
+	readErr := errors.New("disk on fire")
+	scanner := NewScanner(&Lox{}, io.MultiReader(strings.NewReader("print 1;"), iotest.ErrReader(readErr)))
+
+	var err error
+	for err == nil {
+		_, err = scanner.Next()
+	}
+	is.True(errors.Is(err, readErr))
+}
+
+func TestScanner_NumberLiterals(t *testing.T) {
+	tests := []struct {
+		source string
+		want   float64
+	}{
+		{"123", 123},
+		{"123.45", 123.45},
+		{"1_000_000", 1000000},
+		{"1.5e-3", 0.0015},
+		{"2E+2", 200},
+		{"1_0.2_5e1_0", 10.25e10},
+		{"0xFF", 255},
+		{"0Xdead_BEEF", 0xdeadbeef},
+		{"0b1010", 10},
+		{"0o755", 493},
+		{"0", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			is := is2.New(t)
+
+			lox := Lox{}
+			scanner := Scanner{
+				lox:    &lox,
+				source: tt.source,
+			}
+
+			tokens, err := scanner.scanTokens()
+			is.NoErr(err)
+			is.True(!lox.hadError)
+
+			is.Equal(len(tokens), 2)
+			is.Equal(tokens[0].tokenType, NUMBER)
+			is.Equal(tokens[0].lexeme, tt.source)
+			is.Equal(tokens[0].literal, tt.want)
+		})
+	}
+}
+
+func TestScanner_InvalidNumberLiterals(t *testing.T) {
+	tests := []string{
+		"0x",
+		"0b",
+		"0xFG",
+		"0b102",
+		"0o8",
+		"1e",
+		"1e+",
+		"1_",
+		"1__0",
+		"0x_1",
+		"1.5_e3",
+	}
+
+	for _, source := range tests {
+		t.Run(source, func(t *testing.T) {
+			is := is2.New(t)
+
+			lox := Lox{}
+			scanner := Scanner{
+				lox:    &lox,
+				source: source,
+			}
+
+			tokens, err := scanner.scanTokens()
+			is.NoErr(err)
+			is.True(lox.hadError)
+
+			// the whole literal is skipped, as one NUMBER token
+			is.Equal(len(tokens), 2)
+			is.Equal(tokens[0].tokenType, NUMBER)
+		})
+	}
+}
+
+func TestScanner_RawAndMultiLineStrings(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	scanner := Scanner{
+		lox:    &lox,
+		source: "var a = `\\d+\\n\n\"x\"`;\nvar b = \"\"\"\n    one\n      two\n    \"\"\";\nvar c = \"\";",
+		line:   1,
+	}
+
+	tokens, err := scanner.scanTokens()
+	is.NoErr(err)
+	is.True(!lox.hadError)
+
+	is.Equal(tokens[3].literal, "\\d+\\n\n\"x\"")
+	is.Equal(tokens[3].line, 1)
+
+	is.Equal(tokens[6].line, 3)
+	is.Equal(tokens[8].literal, "one\n  two")
+	is.Equal(tokens[8].line, 3)
+
+	// lines inside the strings are counted
+	is.Equal(tokens[10].lexeme, "var")
+	is.Equal(tokens[10].line, 7)
+	is.Equal(tokens[13].literal, "")
+}
+
+func TestScanner_UnterminatedRawAndMultiLineStrings(t *testing.T) {
+	for _, source := range []string{"`abc", "\"\"\"abc\"\"", "\"\"\""} {
+		t.Run(source, func(t *testing.T) {
+			is := is2.New(t)
+
+			lox := Lox{}
+			scanner := Scanner{
+				lox:    &lox,
+				source: source,
+			}
+
+			tokens, err := scanner.scanTokens()
+			is.NoErr(err)
+			is.True(lox.hadError)
+			is.Equal(len(tokens), 1)
+		})
+	}
+}
+
+func TestTrimIndent(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"single line", "abc", "abc"},
+		{"empty", "", ""},
+		{"common indentation", "\n    a\n      b\n    ", "a\n  b"},
+		{"closing quotes less indented", "\n    a\n    b\n  ", "  a\n  b"},
+		{"closing quotes after text", "\n    a\n    b", "a\nb"},
+		{"blank lines", "\n    a\n\n  \n    b\n    ", "a\n\n\nb"},
+		{"text after opening quotes", "a\n    b\n    ", "a\nb"},
+		{"tabs", "\n\ta\n\t\tb\n\t", "a\n\tb"},
+		{"crlf", "\r\n  a\r\n  b\r\n  ", "a\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+			is.Equal(trimIndent(tt.text), tt.want)
+		})
+	}
+}
+
+func TestScanner_BlockComments(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"lone star and slash", "/* a * b / c */ 1"},
+		{"nested", "/* outer /* inner */ still a comment */ 1"},
+		{"deeply nested", "/*/*/**/*/*/ 1"},
+		{"across lines", "/* one\n/* two\n*/\n*/ 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			lox := Lox{}
+			scanner := Scanner{
+				lox:    &lox,
+				source: tt.source,
+			}
+
+			tokens, err := scanner.scanTokens()
+			is.NoErr(err)
+			is.True(!lox.hadError)
+
+			is.Equal(len(tokens), 2)
+			is.Equal(tokens[0].literal, 1.0)
+		})
+	}
+}
+
+func TestScanner_UnterminatedNestedComment(t *testing.T) {
+	is := is2.New(t)
+
+	lox := Lox{}
+	scanner := Scanner{
+		lox:    &lox,
+		source: "/* outer /* inner */ 1",
+	}
+
+	tokens, err := scanner.scanTokens()
+	is.NoErr(err)
+	is.True(lox.hadError)
+	is.Equal(len(tokens), 1)
+}
+
+func TestScanner_DocComments(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		doc    string
+	}{
+		{"line", "/// Adds one.\nvar", "Adds one."},
+		{"several lines", "/// Adds one.\n///\n///   Indented.\nvar", "Adds one.\n\n  Indented."},
+		{"block", "/** Adds one. */ var", "Adds one."},
+		{"starred block", "/**\n * Adds one.\n *\n * Really.\n */\nvar", "Adds one.\n\nReally."},
+		{"ordinary comments", "// a\n/* b */\n//// c\n/*** d */\n/**/ var", ""},
+		{"ordinary comment between", "/// Adds one.\n// TODO\nvar", "Adds one."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is2.New(t)
+
+			lox := Lox{}
+			scanner := Scanner{
+				lox:    &lox,
+				source: tt.source,
+			}
+
+			tokens, err := scanner.scanTokens()
+			is.NoErr(err)
+			is.True(!lox.hadError)
+
+			is.Equal(tokens[0].tokenType, VAR)
+			is.Equal(tokens[0].doc, tt.doc)
+		})
+	}
+}
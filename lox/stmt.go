@@ -1,6 +1,6 @@
 // this file is auto-generated with bin/generate_ast
 // DO NOT EDIT
-package main
+package lox
 
 type Stmt interface {
 	Accept(visitor StmtVisitor) (any, error)
@@ -24,6 +24,7 @@ func (t StmtPrint) Accept(visitor StmtVisitor) (any, error) {
 type StmtVar struct {
 	name        Token
 	initializer Expr
+	doc         string
 }
 
 func (t StmtVar) Accept(visitor StmtVisitor) (any, error) {
@@ -49,6 +50,7 @@ func (t StmtIf) Accept(visitor StmtVisitor) (any, error) {
 }
 
 type StmtWhile struct {
+	keyword   Token
 	condition Expr
 	body      Stmt
 }
@@ -61,6 +63,7 @@ type StmtFunction struct {
 	name   Token
 	params []Token
 	body   StmtBlock
+	doc    string
 }
 
 func (t StmtFunction) Accept(visitor StmtVisitor) (any, error) {
@@ -76,6 +79,37 @@ func (t StmtReturn) Accept(visitor StmtVisitor) (any, error) {
 	return visitor.VisitStmtReturn(t)
 }
 
+type StmtAssert struct {
+	keyword   Token
+	condition Expr
+	message   Expr
+	source    string
+}
+
+func (t StmtAssert) Accept(visitor StmtVisitor) (any, error) {
+	return visitor.VisitStmtAssert(t)
+}
+
+type StmtEnum struct {
+	name    Token
+	members []Token
+	doc     string
+}
+
+func (t StmtEnum) Accept(visitor StmtVisitor) (any, error) {
+	return visitor.VisitStmtEnum(t)
+}
+
+type StmtRecord struct {
+	name   Token
+	fields []Token
+	doc    string
+}
+
+func (t StmtRecord) Accept(visitor StmtVisitor) (any, error) {
+	return visitor.VisitStmtRecord(t)
+}
+
 type StmtVisitor interface {
 	VisitStmtExpression(expr StmtExpression) (any, error)
 	VisitStmtPrint(expr StmtPrint) (any, error)
@@ -85,4 +119,7 @@ type StmtVisitor interface {
 	VisitStmtWhile(expr StmtWhile) (any, error)
 	VisitStmtFunction(expr StmtFunction) (any, error)
 	VisitStmtReturn(expr StmtReturn) (any, error)
+	VisitStmtAssert(expr StmtAssert) (any, error)
+	VisitStmtEnum(expr StmtEnum) (any, error)
+	VisitStmtRecord(expr StmtRecord) (any, error)
 }
@@ -0,0 +1,82 @@
+package lox
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// suggestName picks the candidate closest to name by edit distance, for "did you mean ...?" hints.
+// Candidates further away than a third of the name's length (but at least one edit) aren't suggested.
+func suggestName(name string, candidates []string) (string, bool) {
+	maxDistance := utf8.RuneCountInString(name) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	// sorted so that ties are broken the same way every time
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+
+	best := ""
+	bestDistance := maxDistance + 1
+	for _, candidate := range sorted {
+		if candidate == name {
+			continue
+		}
+
+		if d := editDistance(name, candidate); d < bestDistance {
+			best = candidate
+			bestDistance = d
+		}
+	}
+
+	return best, best != ""
+}
+
+// editDistance is the optimal string alignment distance between a and b: the number of
+// single-rune insertions, deletions, substitutions and adjacent transpositions to turn one into the other.
+func editDistance(a string, b string) int {
+	ar := []rune(a)
+	br := []rune(b)
+
+	// rows for i-2, i-1 and i
+	prevPrev := make([]int, len(br)+1)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				curr[j] = minInt(curr[j], prevPrev[j-2]+1)
+			}
+		}
+
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+
+	return prev[len(br)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, v := range rest {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
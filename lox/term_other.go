@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package lox
+
+import "errors"
+
+// makeRaw isn't supported on this platform, so the REPL reads whole lines without editing.
+func makeRaw(fd int) (func() error, error) {
+	return nil, errors.New("raw mode isn't supported on this platform")
+}
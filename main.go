@@ -1,126 +1,115 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-)
 
-type Lox struct {
-	hadError        bool
-	hadRuntimeError bool
-}
+	"github.com/abaayd01/golox/lox"
+)
 
-func (l *Lox) reportError(line int, message string) {
-	l.report(line, "", message)
-}
+func main() {
+	maxCallDepth := flag.Int("max-call-depth", lox.DefaultMaxCallDepth, "maximum depth of nested Lox function calls")
+	stream := flag.Bool("stream", false, "run the script, from a file or stdin, while it is being read, instead of reading and checking it all first")
+	timeout := flag.Duration("timeout", 0, "stop the script if it runs for longer than this, e.g. 30s (default no limit)")
+	flag.Parse()
 
-func (l *Lox) report(line int, where string, message string) {
-	_, _ = fmt.Fprintf(os.Stderr, "[line: %d] Error%s: %s\n", line, where, message)
-	l.hadError = true
-}
+	args := flag.Args()
 
-func (l *Lox) error(token Token, message string) {
-	if token.tokenType == EOF {
-		l.report(token.line, " at end", message)
-	} else {
-		l.report(token.line, fmt.Sprintf(" at '%s'", token.lexeme), message)
+	if len(args) > 1 {
+		log.Fatal("Invalid usage")
 	}
-}
 
-func (l *Lox) runtimeError(err RuntimeError) {
-	_, _ = fmt.Fprintf(os.Stderr, "[line %d] %s\n", err.Token.line, err.Error())
-	l.hadRuntimeError = true
-}
+	if *maxCallDepth < 1 || *maxCallDepth > lox.MaxCallDepthLimit {
+		log.Fatalf("Invalid usage: -max-call-depth must be between 1 and %d", lox.MaxCallDepthLimit)
+	}
 
-func (l *Lox) run(source string) error {
-	scanner := Scanner{
-		lox:    l,
-		source: source,
+	engine := lox.NewEngine(lox.WithMaxCallDepth(*maxCallDepth))
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
 	}
-	tokens, err := scanner.scanTokens()
-	if err != nil {
-		return err
+
+	var err error
+	switch {
+	case len(args) == 1 && *stream:
+		err = streamFile(ctx, engine, args[0])
+	case len(args) == 1:
+		err = runFile(ctx, engine, args[0])
+	case *stream:
+		err = engine.RunStream(ctx, os.Stdin)
+	case !isTerminal(os.Stdin):
+		// a program piped in on stdin is read and checked in full before any of it runs, like a file
+		err = runStdin(ctx, engine)
+	default:
+		_ = engine.RunPrompt()
+		return
 	}
 
-	parser := Parser{
-		Lox:     l,
-		Tokens:  tokens,
-		current: 0,
+	if errors.Is(err, lox.ParseError) {
+		engine.Render(err)
+		os.Exit(65)
 	}
-	statements, err := parser.Parse()
-	if err != nil { // stop if there was a parsing error
-		return err
+	var runtimeErr lox.RuntimeError
+	if errors.As(err, &runtimeErr) {
+		engine.Render(err)
+		os.Exit(70)
 	}
-
-	e := NewGlobalEnvironment()
-	interpreter := Interpreter{
-		Lox:         l,
-		Environment: e,
+	if err != nil {
+		log.Fatalln(err)
 	}
-
-	_ = interpreter.InterpretStatements(statements)
-	//_, _ = interpreter.InterpretExpression(expr) // don't blow up if there's runtime errors?
-
-	// temporary AstPrinter code
-	//printer := AstPrinter{}
-	//fmt.Println(printer.Print(expr))
-	return nil
 }
 
-func (l *Lox) runFile(path string) error {
+func runFile(ctx context.Context, engine *lox.Engine, path string) error {
 	log.Printf("runFile, path: %s", path)
 	bytes, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("runFile error, os.ReadFile: %w", err)
 	}
 
-	// TODO: re-think how errors are propagated up from Parser + Interpreter into Lox entry point.
-	// Need to figure out how to best deal with runtime errors vs. parsing errors.
-	// At the moment run doesn't return an error for runtime errors, the Interpreter will just set the hadRuntimeError flag.
-	return l.run(string(bytes))
-}
+	program, err := engine.CompileSource(path, string(bytes))
+	if err != nil {
+		return err
+	}
 
-func (l *Lox) printPrompt() {
-	fmt.Printf("> ")
+	return engine.Run(ctx, program)
 }
 
-func (l *Lox) runPrompt() error {
-	reader := bufio.NewScanner(os.Stdin)
-	l.printPrompt()
-	for reader.Scan() {
-		// just echoing out the input for now
-		cmd := reader.Text()
-		fmt.Println(cmd)
-		err := l.run(cmd)
-		if err != nil {
-			return err
-		}
-		l.printPrompt()
+func runStdin(ctx context.Context, engine *lox.Engine) error {
+	bytes, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("runStdin error, io.ReadAll: %w", err)
 	}
-	// Print an additional line if we encountered an EOF character
-	fmt.Println()
-	return nil
-}
-
-func main() {
-	args := os.Args
 
-	if len(args) > 2 {
-		log.Fatal("Invalid usage")
+	program, err := engine.Compile(string(bytes))
+	if err != nil {
+		return err
 	}
 
-	l := Lox{}
+	return engine.Run(ctx, program)
+}
 
-	if len(args) == 2 {
-		err := l.runFile(args[1])
-		if err != nil {
-			log.Fatalln(err)
-		}
+func streamFile(ctx context.Context, engine *lox.Engine, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("streamFile error, os.Open: %w", err)
+	}
+	defer f.Close()
 
-		return
+	return engine.RunStream(ctx, f)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
 	}
 
-	_ = l.runPrompt()
+	return info.Mode()&os.ModeCharDevice != 0
 }